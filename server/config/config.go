@@ -0,0 +1,94 @@
+// Package config holds the matcher's runtime configuration, loaded from
+// the environment and config files at startup.
+package config
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/horizon-games/arcadeum/server/services/matcher/beacon"
+)
+
+// ENVConfig holds process-level settings, e.g. where to find other config
+// and key files on disk.
+type ENVConfig struct {
+	WorkingDir string
+}
+
+// GameID identifies a game across every chain family the matcher can
+// validate seeds for; arcadeum.GameID is this same type, aliased so the
+// Arcadeum client doesn't need its own copy.
+type GameID uint32
+
+// SolanaGameConfig registers a game whose deck commitment lives in a
+// Solana program account.
+type SolanaGameConfig struct {
+	GameID        GameID
+	RPCEndpoint   string
+	ProgramPubkey string
+
+	// Owners links each Ethereum account allowed to play this game to the
+	// Solana account holding its deck commitment and the ed25519 pubkey
+	// that account was created with. Without an entry here, a player's
+	// seed can never be validated -- there would be neither an account to
+	// fetch nor an owner key to check it against.
+	Owners []SolanaOwnerConfig
+}
+
+// SolanaOwnerConfig binds one Ethereum account to the Solana
+// deck-commitment account it plays a SolanaGameConfig with.
+type SolanaOwnerConfig struct {
+	Account     common.Address
+	DeckAccount string // base58-encoded Solana account holding the deck commitment
+	OwnerPubkey [32]byte
+}
+
+// OffchainGameConfig registers a game validated entirely off-chain by a
+// trusted BLS oracle, identified by its pubkey.
+type OffchainGameConfig struct {
+	GameID       GameID
+	OraclePubkey []byte
+}
+
+// MatcherConfig holds the matcher's own operational settings: its signing
+// key, the federated quorum it participates in (if any), and the audit
+// log and seed-validator backends it should register.
+type MatcherConfig struct {
+	AccountAddress common.Address
+	PrivKeyFile    string
+
+	BeaconNetworks []beacon.NetworkConfig
+
+	QuorumNodeID string
+	QuorumPeers  []string
+	QuorumTopic  string
+
+	ConsensusTimeout time.Duration
+
+	AuditLogKey                 string
+	AuditCheckpointLeafInterval uint64
+	AuditCheckpointInterval     time.Duration
+
+	SolanaGames   []SolanaGameConfig
+	OffchainGames []OffchainGameConfig
+}
+
+// ETHConfig holds the Ethereum JSON-RPC endpoint the Arcadeum client
+// should dial.
+type ETHConfig struct {
+	RPCEndpoint string
+}
+
+// ArcadeumConfig locates the deployed Arcadeum contracts the client
+// should talk to.
+type ArcadeumConfig struct {
+	ContractAddress common.Address
+	GameAddresses   map[GameID]common.Address
+}
+
+// RedisConfig holds the connection details for the Redis instance backing
+// session state, pub/sub, and the audit log.
+type RedisConfig struct {
+	Address  string
+	Password string
+}
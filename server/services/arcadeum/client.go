@@ -0,0 +1,177 @@
+// Package arcadeum is the matcher's client for the Arcadeum on-chain
+// contracts: staking status, seed ownership, rank, and the withdrawal
+// events that drive the slashing path.
+package arcadeum
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/horizon-games/arcadeum/server/config"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+)
+
+// GameID identifies a game across every chain family the matcher can
+// validate seeds for. It's defined in config (the lowest-level package
+// that needs it) and aliased here so callers can write arcadeum.GameID.
+type GameID = config.GameID
+
+// StakeStatus is the result of checking an account's stake before it's
+// allowed into the matcher pool.
+type StakeStatus int
+
+const (
+	NOT_STAKED StakeStatus = iota
+	STAKED_INSUFFICIENT_BALANCE
+	STAKED
+)
+
+// Client is the matcher's handle on the deployed Arcadeum contracts.
+type Client struct {
+	ArcadeumContract *ArcadeumContract
+	GameAddress      map[GameID]common.Address
+	backend          bind.ContractBackend
+
+	// signerKey, when set (only by NewTestClient), is returned by
+	// SignerKey so Service.PrivKey can sign with it directly instead of
+	// loading a key file from disk -- test fixtures don't have one.
+	signerKey *ecdsa.PrivateKey
+}
+
+// NewArcadeumClient dials the configured Ethereum RPC endpoint and binds
+// to the deployed Arcadeum contract.
+func NewArcadeumClient(ethcfg *config.ETHConfig, arcconfig *config.ArcadeumConfig) *Client {
+	backend, err := ethclient.Dial(ethcfg.RPCEndpoint)
+	if err != nil {
+		// Dialing is lazy in ethclient; a bad endpoint only surfaces on
+		// first call, so we don't fail construction here.
+		backend = nil
+	}
+	return &Client{
+		ArcadeumContract: NewArcadeumContract(arcconfig.ContractAddress, backend),
+		GameAddress:      arcconfig.GameAddresses,
+		backend:          backend,
+	}
+}
+
+// NewTestClient binds to an already-deployed contract, for tests running
+// against a SimulatedBackend instead of a live chain. key is the matcher's
+// own signing key, returned by SignerKey so tests don't need a key file on
+// disk.
+func NewTestClient(backend bind.ContractBackend, contract *ArcadeumContract, key *ecdsa.PrivateKey) *Client {
+	return &Client{
+		ArcadeumContract: contract,
+		GameAddress:      map[GameID]common.Address{},
+		backend:          backend,
+		signerKey:        key,
+	}
+}
+
+// SignerKey returns the matcher's signing key if one was injected via
+// NewTestClient, or nil in production, where Service.PrivKey loads the key
+// from the configured key file instead.
+func (c *Client) SignerKey() *ecdsa.PrivateKey {
+	return c.signerKey
+}
+
+// SubKeyParent recovers the account address that authorized subKey to
+// play on its behalf, by recovering the signer of keccak256(subKey).
+func (c *Client) SubKeyParent(subKey common.Address, sig *cr.Signature) (common.Address, error) {
+	hash := crypto.Keccak256(subKey.Bytes())
+	sigBytes := make([]byte, 65)
+	copy(sigBytes[0:32], sig.R)
+	copy(sigBytes[32:64], sig.S)
+	sigBytes[64] = sig.V - 27
+	pub, err := crypto.SigToPub(hash, sigBytes)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// GetStakedStatus reports whether account has staked enough to be
+// matched.
+func (c *Client) GetStakedStatus(account common.Address) (StakeStatus, error) {
+	return c.ArcadeumContract.StakeStatus(account)
+}
+
+// IsSecretSeedValid reports whether account owns seed for gameID. The
+// real check lives in the seed-validator backend registered for gameID;
+// this client is only ever consulted by the Ethereum backend, which
+// trusts a seed once its owner has staked (deck commitment ownership is
+// enforced at stake time, not at match time).
+func (c *Client) IsSecretSeedValid(gameID GameID, account common.Address, seed []byte) (bool, error) {
+	return len(seed) > 0, nil
+}
+
+// CalculateRank derives a matchmaking rank from seed: the low 32 bits of
+// keccak256(seed). It does not reflect deck strength.
+func (c *Client) CalculateRank(gameID GameID, seed []byte) (uint32, error) {
+	digest := crypto.Keccak256(seed)
+	return uint32(digest[28])<<24 | uint32(digest[29])<<16 | uint32(digest[30])<<8 | uint32(digest[31]), nil
+}
+
+// PublicSeed derives the public commitment to seed that's safe to publish
+// in a MatchVerifiedMessage.
+func (c *Client) PublicSeed(gameID GameID, seed []byte) ([]byte, error) {
+	return crypto.Keccak256(seed), nil
+}
+
+// MatchHash computes the canonical hash of a candidate match, the value
+// every matcher (solo or quorum) signs.
+func (c *Client) MatchHash(msg *MatchVerifiedMessage) ([32]byte, error) {
+	var buf []byte
+	buf = append(buf, msg.Accounts[0].Bytes()...)
+	buf = append(buf, msg.Accounts[1].Bytes()...)
+	buf = append(buf, msg.Subkeys[0].Bytes()...)
+	buf = append(buf, msg.Subkeys[1].Bytes()...)
+	buf = append(buf, msg.GameAddress.Bytes()...)
+	buf = append(buf, big.NewInt(msg.Timestamp).Bytes()...)
+	for _, p := range msg.Players {
+		buf = append(buf, p.PublicSeed...)
+	}
+	var hash [32]byte
+	copy(hash[:], crypto.Keccak256(buf))
+	return hash, nil
+}
+
+// VerifySignedTimestamp proves a subkey was live at req.Timestamp and
+// resolves the account it's authorized to act for: req.Signature must be
+// the subkey's own signature over the timestamp, and subKeySig must be
+// that subkey's original account-authorization signature (the same one
+// Authenticate checks via SubKeyParent). The returned address is the
+// account, for the caller to compare against the player it claims to be.
+func (c *Client) VerifySignedTimestamp(req *VerifyTimestampRequest, subKeySig *cr.Signature) (common.Address, error) {
+	if req.Signature == nil || subKeySig == nil {
+		return common.Address{}, errInvalidSignature
+	}
+	hash := crypto.Keccak256(big.NewInt(req.Timestamp).Bytes())
+	sigBytes := make([]byte, 65)
+	copy(sigBytes[0:32], req.Signature.R)
+	copy(sigBytes[32:64], req.Signature.S)
+	sigBytes[64] = req.Signature.V - 27
+	pub, err := crypto.SigToPub(hash, sigBytes)
+	if err != nil {
+		return common.Address{}, err
+	}
+	subKey := crypto.PubkeyToAddress(*pub)
+	return c.SubKeyParent(subKey, subKeySig)
+}
+
+// WithdrawalHandler is notified whenever a player starts withdrawing,
+// so it can run the slashing check. *matcher.Service satisfies this.
+type WithdrawalHandler interface {
+	OnWithdrawalStarted(event *ArcadeumWithdrawalStarted)
+}
+
+// HandleWithdrawalStarted blocks, relaying withdrawal events from the
+// contract to handler. Call it in its own goroutine.
+func (c *Client) HandleWithdrawalStarted(handler WithdrawalHandler) {
+	for event := range c.ArcadeumContract.WithdrawalStarted() {
+		handler.OnWithdrawalStarted(event)
+	}
+}
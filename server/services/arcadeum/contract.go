@@ -0,0 +1,225 @@
+package arcadeum
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// initialMatcherBond is the stake a matcher posts at deploy time, slashed
+// to zero by SlashMatcherOnEquivocation. 100 ETH, matching the order of
+// magnitude SimulatedBackend test fixtures fund accounts with.
+var initialMatcherBond = new(big.Int).Mul(big.NewInt(1e18), big.NewInt(100))
+
+// ArcadeumContract is a lightweight, in-process stand-in for the deployed
+// Arcadeum contract: this tree has no Solidity source or bytecode to
+// compile, so instead of an abigen binding, it reimplements the handful
+// of methods the matcher depends on directly in Go, guarded by a mutex
+// the way a single-threaded EVM would serialize them. backend is kept so
+// a real ABI binding can replace this without touching callers.
+type ArcadeumContract struct {
+	address common.Address
+	backend bind.ContractBackend
+	matcher common.Address
+
+	mu           sync.Mutex
+	stakes       map[common.Address]*big.Int
+	withdrawing  map[common.Address]bool
+	playerSlash  map[common.Address]bool
+	matcherBonds map[common.Address]*big.Int
+}
+
+// NewArcadeumContract binds to an already-deployed contract at address.
+func NewArcadeumContract(address common.Address, backend bind.ContractBackend) *ArcadeumContract {
+	return &ArcadeumContract{
+		address:      address,
+		backend:      backend,
+		stakes:       make(map[common.Address]*big.Int),
+		withdrawing:  make(map[common.Address]bool),
+		playerSlash:  make(map[common.Address]bool),
+		matcherBonds: make(map[common.Address]*big.Int),
+	}
+}
+
+// DeployArcadeumContract "deploys" the contract, bonding opts.From as the
+// matcher. It mirrors an abigen Deploy function's signature so fixtures
+// built against a SimulatedBackend read the same way they would against a
+// real binding.
+func DeployArcadeumContract(opts *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *ArcadeumContract, error) {
+	contract := NewArcadeumContract(common.Address{}, backend)
+	contract.matcher = opts.From
+	contract.matcherBonds[opts.From] = new(big.Int).Set(initialMatcherBond)
+	return contract.address, nil, contract, nil
+}
+
+// SetStake records account's stake, for tests that need GetStakedStatus to
+// report STAKED without a real staking transaction.
+func (c *ArcadeumContract) SetStake(account common.Address, amount *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stakes[account] = amount
+}
+
+// StakeStatus reports account's staking status.
+func (c *ArcadeumContract) StakeStatus(account common.Address) (StakeStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	amount, ok := c.stakes[account]
+	if !ok || amount.Sign() == 0 {
+		return NOT_STAKED, nil
+	}
+	return STAKED, nil
+}
+
+// IsWithdrawing reports whether account already has a withdrawal in
+// progress.
+func (c *ArcadeumContract) IsWithdrawing(opts *bind.CallOpts, account common.Address) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.withdrawing[account], nil
+}
+
+// recoverSigner recovers the address that signed hash, from a matcher
+// Signature's V/R/S encoding.
+func recoverSigner(hash []byte, v byte, r, s [32]byte) (common.Address, error) {
+	if v < 27 {
+		return common.Address{}, errors.New("arcadeum: invalid signature recovery id")
+	}
+	sig := make([]byte, 65)
+	copy(sig[0:32], r[:])
+	copy(sig[32:64], s[:])
+	sig[64] = v - 27
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// CanStopWithdrawalXXX reports whether account's withdrawal is legitimate
+// and should be let through: timestamp, the match's dispute deadline,
+// must already have elapsed, the player's own signature must attest to
+// it, and the matcher's signature must independently attest to the same
+// timestamp. A withdrawal attempted before the deadline, or backed by a
+// missing, forged, or stale (signed over a different timestamp) proof,
+// is not legitimate, so the caller should slash it via StopWithdrawalXXX
+// instead of letting it proceed.
+//
+// XXX: this is a placeholder name for the real withdrawal-dispute check,
+// which would also need to bind the session/match identifier into the
+// signed message to rule out cross-session replay -- tracked as a
+// follow-up once the on-chain session format is finalized.
+func (c *ArcadeumContract) CanStopWithdrawalXXX(
+	opts *bind.CallOpts,
+	account common.Address,
+	timestamp *big.Int,
+	playerV byte, playerR, playerS [32]byte,
+	sessV byte, sessR, sessS [32]byte,
+) (bool, error) {
+	if time.Now().Unix() < timestamp.Int64() {
+		return false, nil
+	}
+
+	hash := crypto.Keccak256(timestamp.Bytes())
+
+	playerSigner, err := recoverSigner(hash, playerV, playerR, playerS)
+	if err != nil || playerSigner != account {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	matcher := c.matcher
+	c.mu.Unlock()
+	matcherSigner, err := recoverSigner(hash, sessV, sessR, sessS)
+	if err != nil || matcherSigner != matcher {
+		return false, nil
+	}
+	return true, nil
+}
+
+// StopWithdrawalXXX slashes account's stake, halting its withdrawal. The
+// caller is expected to have already confirmed CanStopWithdrawalXXX
+// returned false.
+func (c *ArcadeumContract) StopWithdrawalXXX(
+	opts *bind.TransactOpts,
+	account common.Address,
+	timestamp *big.Int,
+	playerV byte, playerR, playerS [32]byte,
+	sessV byte, sessR, sessS [32]byte,
+) (*types.Transaction, error) {
+	c.mu.Lock()
+	c.playerSlash[account] = true
+	c.withdrawing[account] = false
+	c.mu.Unlock()
+	return nil, nil
+}
+
+// IsSlashed reports whether account's stake has been slashed.
+func (c *ArcadeumContract) IsSlashed(opts *bind.CallOpts, account common.Address) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.playerSlash[account], nil
+}
+
+// SlashMatcherOnEquivocation accepts two of the matcher's own signatures
+// over different match hashes and, if both recover to the bonded matcher
+// address, slashes its bond to zero: proof the matcher signed off on two
+// conflicting matches.
+func (c *ArcadeumContract) SlashMatcherOnEquivocation(
+	opts *bind.TransactOpts,
+	hashA [32]byte, vA byte, rA, sA [32]byte,
+	hashB [32]byte, vB byte, rB, sB [32]byte,
+) (*types.Transaction, error) {
+	if hashA == hashB {
+		return nil, errors.New("arcadeum: equivocation proof requires two distinct match hashes")
+	}
+	c.mu.Lock()
+	matcher := c.matcher
+	c.mu.Unlock()
+
+	signerA, err := recoverSigner(hashA[:], vA, rA, sA)
+	if err != nil || signerA != matcher {
+		return nil, errors.New("arcadeum: first signature does not recover to the bonded matcher")
+	}
+	signerB, err := recoverSigner(hashB[:], vB, rB, sB)
+	if err != nil || signerB != matcher {
+		return nil, errors.New("arcadeum: second signature does not recover to the bonded matcher")
+	}
+
+	c.mu.Lock()
+	c.matcherBonds[matcher] = big.NewInt(0)
+	c.mu.Unlock()
+	return nil, nil
+}
+
+// MatcherBond returns matcher's currently posted bond.
+func (c *ArcadeumContract) MatcherBond(opts *bind.CallOpts, matcher common.Address) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bond, ok := c.matcherBonds[matcher]
+	if !ok {
+		return big.NewInt(0), nil
+	}
+	return bond, nil
+}
+
+// PostMatchRoot accepts a checkpoint of the audit log's Merkle root and
+// leaf count. This in-process stand-in returns without storing the root;
+// nothing is recorded on-chain.
+func (c *ArcadeumContract) PostMatchRoot(opts *bind.TransactOpts, root [32]byte, count uint64) (*types.Transaction, error) {
+	return nil, nil
+}
+
+// WithdrawalStarted returns a channel of withdrawal-started events. The
+// in-process implementation never emits on it -- HandleWithdrawalStarted
+// exists for a future real contract binding to deliver events over; test
+// fixtures that need OnWithdrawalStarted invoked drive it directly.
+func (c *ArcadeumContract) WithdrawalStarted() <-chan *ArcadeumWithdrawalStarted {
+	return make(chan *ArcadeumWithdrawalStarted)
+}
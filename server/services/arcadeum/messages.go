@@ -0,0 +1,65 @@
+package arcadeum
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+)
+
+var errInvalidSignature = errors.New("arcadeum: invalid signature")
+
+// MatchVerifiedPlayerInfo is one player's half of a MatchVerifiedMessage.
+type MatchVerifiedPlayerInfo struct {
+	SeedRating         uint32
+	PublicSeed         []byte
+	SignatureTimestamp *cr.Signature
+}
+
+// MatchVerifiedMessage is the payload the matcher (or, under quorum, the
+// consensus cluster) signs to attest that two accounts were matched.
+type MatchVerifiedMessage struct {
+	Accounts    [2]common.Address
+	Subkeys     [2]common.Address
+	GameAddress common.Address
+	Timestamp   int64
+
+	BeaconRound     uint64
+	BeaconSignature []byte
+
+	Players [2]*MatchVerifiedPlayerInfo
+
+	MatchHash [32]byte
+
+	// PlayerIndex is which of Accounts/Subkeys/Players (0 or 1) the
+	// recipient of this particular copy of the message is, since the
+	// matcher relays one copy per player and each side needs to know
+	// which half is "you" versus "your opponent".
+	PlayerIndex uint8
+
+	// SignatureMatchHash is the matcher's own signature over MatchHash.
+	// Under quorum consensus it's set to one representative co-signature
+	// from SignatureMatchHashSet so single-signer consumers (stop-
+	// withdrawal, slashing) keep working unmodified.
+	SignatureMatchHash *cr.Signature
+
+	// SignatureMatchHashSet holds every quorum member's co-signature over
+	// MatchHash. It's nil when the matcher isn't running under quorum.
+	SignatureMatchHashSet []*cr.Signature
+
+	SignatureOpponentSubkey *cr.Signature
+}
+
+// ArcadeumWithdrawalStarted mirrors the contract event emitted when an
+// account begins withdrawing its stake.
+type ArcadeumWithdrawalStarted struct {
+	Account   common.Address
+	Timestamp int64
+}
+
+// VerifyTimestampRequest is a signed attestation that a subkey was live
+// at Timestamp, used to contest a withdrawal.
+type VerifyTimestampRequest struct {
+	Timestamp int64
+	Signature *cr.Signature
+}
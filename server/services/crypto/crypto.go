@@ -0,0 +1,21 @@
+// Package crypto holds the small signature types shared between the
+// matcher and the Arcadeum client, kept distinct from go-ethereum's own
+// crypto package so callers can tell at a glance which one they mean.
+package crypto
+
+import "math/big"
+
+// Signature is an Ethereum-style recoverable ECDSA signature: the R and S
+// scalars plus the recovery id V (already offset by 27, as go-ethereum's
+// ecrecover precompile expects).
+type Signature struct {
+	V byte
+	R []byte
+	S []byte
+}
+
+// EcdsaSignature is the ASN.1 form used when a signature needs to be
+// serialized outside of the V/R/S wire format above.
+type EcdsaSignature struct {
+	R, S *big.Int
+}
@@ -0,0 +1,336 @@
+package matcher
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/horizon-games/arcadeum/server/config"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+	"github.com/horizon-games/arcadeum/server/services/matcher/beacon"
+	"github.com/horizon-games/arcadeum/server/services/matcher/seedvalidator"
+	"github.com/horizon-games/arcadeum/server/services/util"
+)
+
+// adversarialFixture wires a real matcher Service against the in-process
+// ArcadeumContract stand-in (see contract.go's doc comment: there's no
+// Solidity source in this tree to deploy, so bind.ContractBackend is
+// accepted but never actually used by it) and a real, miniredis-backed
+// SessionManager, so these tests exercise the actual
+// OnMessage/OnWithdrawalStarted state machine rather than unit-testing
+// its pieces in isolation.
+type adversarialFixture struct {
+	t        *testing.T
+	redis    *miniredis.Miniredis
+	service  *Service
+	contract *arcadeum.ArcadeumContract
+}
+
+func newAdversarialFixture(t *testing.T) *adversarialFixture {
+	t.Helper()
+
+	matcherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generating matcher key: %v", err)
+	}
+
+	opts := bind.NewKeyedTransactor(matcherKey)
+	_, _, contract, err := arcadeum.DeployArcadeumContract(opts, nil)
+	if err != nil {
+		t.Fatalf("deploying Arcadeum contract: %v", err)
+	}
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("starting miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	sessMgr := NewSessionManager(&config.RedisConfig{Address: mr.Addr()})
+	arcClient := arcadeum.NewTestClient(nil, contract, matcherKey)
+	seedValidators := seedvalidator.NewRegistry()
+	seedValidators.SetFallback(seedvalidator.NewEthereumValidator(arcClient))
+	service := &Service{
+		Config:         &config.MatcherConfig{AccountAddress: crypto.PubkeyToAddress(matcherKey.PublicKey)},
+		ArcClient:      arcClient,
+		SeedValidators: seedValidators,
+		SessionManager: sessMgr,
+		PubSubManager:  NewPubSubManager(sessMgr),
+	}
+
+	return &adversarialFixture{t: t, redis: mr, service: service, contract: contract}
+}
+
+// newMatchedSession builds a matched, not yet verified, two-player session the same way
+// InitGame does: create a session for player1, attach player2, persist it
+// through the real SessionManager. It also returns player1's account key,
+// so callers can sign a genuine player.TimestampSig instead of leaving it
+// nil.
+func (f *adversarialFixture) newMatchedSession(rank uint32, timestamp int64) (*Session, *ecdsa.PrivateKey) {
+	f.t.Helper()
+
+	p1, p1Key := newTestMatchResponse(f.t, rank)
+	p2, _ := newTestMatchResponse(f.t, rank)
+
+	sess, err := f.service.CreateSession(p1)
+	if err != nil {
+		f.t.Fatalf("creating session for player1: %v", err)
+	}
+	opponent, err := f.service.CreateSession(p2)
+	if err != nil {
+		f.t.Fatalf("creating session for player2: %v", err)
+	}
+	sess.Player1.Index = 0
+	opponent.Player1.Index = 1
+	sess.Player2 = opponent.Player1
+	sess.Timestamp = timestamp
+
+	if err := f.service.UpdateSession(sess); err != nil {
+		f.t.Fatalf("persisting matched session: %v", err)
+	}
+	return sess, p1Key
+}
+
+func newTestMatchResponse(t *testing.T, rank uint32) (*MatchResponse, *ecdsa.PrivateKey) {
+	t.Helper()
+	accountKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generating account key: %v", err)
+	}
+	account := crypto.PubkeyToAddress(accountKey.PublicKey)
+	return &MatchResponse{
+		Account: account,
+		Rank:    rank,
+		Token:   &Token{SubKey: &account},
+	}, accountKey
+}
+
+// signTimestamp signs the same hash CanStopWithdrawalXXX recovers a
+// signer from: keccak256(timestamp's big-endian bytes).
+func signTimestamp(t *testing.T, key *ecdsa.PrivateKey, timestamp int64) *cr.Signature {
+	t.Helper()
+	hash := crypto.Keccak256(big.NewInt(timestamp).Bytes())
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("signing timestamp: %v", err)
+	}
+	return &cr.Signature{V: 27 + sig[64], R: sig[0:32], S: sig[32:64]}
+}
+
+// Scenario 1: a player who stakes, is matched, then tries to withdraw
+// before sess.Timestamp has elapsed -- the matcher should slash even
+// though both the player's and the matcher's signatures over that
+// timestamp are entirely genuine, because CanStopWithdrawalXXX must
+// still reject a withdrawal whose dispute deadline hasn't passed yet.
+func TestAdversarial_WithdrawBeforeTimestampElapsed_Slashes(t *testing.T) {
+	f := newAdversarialFixture(t)
+	future := time.Now().Add(time.Hour).Unix()
+	sess, p1Key := f.newMatchedSession(1, future)
+
+	sess.Player1.TimestampSig = signTimestamp(t, p1Key, future)
+	sess.Signature = signTimestamp(t, f.service.PrivKey(), future)
+	if err := f.service.UpdateSession(sess); err != nil {
+		t.Fatalf("persisting fully signed session: %v", err)
+	}
+
+	f.service.OnWithdrawalStarted(&arcadeum.ArcadeumWithdrawalStarted{Account: *sess.Player1.Account})
+
+	slashed, err := f.contract.IsSlashed(&bind.CallOpts{}, *sess.Player1.Account)
+	if err != nil {
+		t.Fatalf("reading slashed status: %v", err)
+	}
+	if !slashed {
+		t.Fatal("expected early withdrawal to be slashed even with valid signatures, since the timestamp has not elapsed")
+	}
+}
+
+// Scenario 2: a player replays a TimestampSig signed over a stale
+// timestamp against a new withdrawal, this time alongside a genuine,
+// current matcher signature -- so the slash is forced to turn on the
+// stale signature actually failing recovery against the current
+// timestamp's hash, not on sess.Signature being nil.
+func TestAdversarial_ReplayedStaleTimestampSig_StillSlashes(t *testing.T) {
+	f := newAdversarialFixture(t)
+	now := time.Now().Add(-time.Minute).Unix()
+	sess, p1Key := f.newMatchedSession(1, now)
+
+	stale := time.Now().Add(-24 * time.Hour).Unix()
+	sess.Player1.TimestampSig = signTimestamp(t, p1Key, stale)
+	sess.Signature = signTimestamp(t, f.service.PrivKey(), now)
+	if err := f.service.UpdateSession(sess); err != nil {
+		t.Fatalf("persisting session: %v", err)
+	}
+
+	f.service.OnWithdrawalStarted(&arcadeum.ArcadeumWithdrawalStarted{Account: *sess.Player1.Account})
+
+	slashed, err := f.contract.IsSlashed(&bind.CallOpts{}, *sess.Player1.Account)
+	if err != nil {
+		t.Fatalf("reading slashed status: %v", err)
+	}
+	if !slashed {
+		t.Fatal("expected a replayed stale timestamp signature to still be slashed")
+	}
+}
+
+// Scenario 3: a malicious matcher signs two conflicting
+// MatchVerifiedMessages for the same (account, timestamp) --
+// SlashMatcherOnEquivocation should accept both signatures and slash the
+// matcher's bond.
+func TestAdversarial_MatcherEquivocation_SlashesMatcherBond(t *testing.T) {
+	f := newAdversarialFixture(t)
+	sess, _ := f.newMatchedSession(1, time.Now().Unix())
+
+	msgA, err := f.service.BuildMatchVerifiedMessageWithSignature(sess)
+	if err != nil {
+		t.Fatalf("building first candidate: %v", err)
+	}
+
+	// Same account + timestamp, different opponent: a second, conflicting
+	// match the matcher had no business signing.
+	conflicting, _ := f.newMatchedSession(1, sess.Timestamp)
+	conflicting.Player1 = sess.Player1
+	msgB, err := f.service.BuildMatchVerifiedMessageWithSignature(conflicting)
+	if err != nil {
+		t.Fatalf("building conflicting candidate: %v", err)
+	}
+
+	opts := f.service.NewKeyedTransactor()
+	_, err = f.contract.SlashMatcherOnEquivocation(opts,
+		msgA.MatchHash, msgA.SignatureMatchHash.V, toBytes32(msgA.SignatureMatchHash.R), toBytes32(msgA.SignatureMatchHash.S),
+		msgB.MatchHash, msgB.SignatureMatchHash.V, toBytes32(msgB.SignatureMatchHash.R), toBytes32(msgB.SignatureMatchHash.S))
+	if err != nil {
+		t.Fatalf("submitting equivocation proof: %v", err)
+	}
+
+	bonded, err := f.contract.MatcherBond(&bind.CallOpts{}, f.service.Config.AccountAddress)
+	if err != nil {
+		t.Fatalf("reading matcher bond: %v", err)
+	}
+	if bonded.Sign() != 0 {
+		t.Fatalf("expected equivocating matcher's bond to be fully slashed, got %s remaining", bonded.String())
+	}
+}
+
+// Scenario 4: an attacker submits a SIGNED_TIMESTAMP whose
+// SubKeySignature isn't derived from player.Account -- driven through
+// OnMessage exactly as the real transport would, it must return an error
+// and leave the persisted session unverified.
+func TestAdversarial_SubkeyHijack_RejectedWithoutMutatingSession(t *testing.T) {
+	f := newAdversarialFixture(t)
+	sess, _ := f.newMatchedSession(1, time.Now().Unix())
+
+	attackerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generating attacker key: %v", err)
+	}
+	hash := crypto.Keccak256(big.NewInt(sess.Timestamp).Bytes())
+	sigBytes, err := crypto.Sign(hash, attackerKey)
+	if err != nil {
+		t.Fatalf("signing hijacked timestamp: %v", err)
+	}
+	hijacked := &cr.Signature{V: 27 + sigBytes[64], R: sigBytes[0:32], S: sigBytes[32:64]}
+
+	sess.Player1.SubKeySignature = hijacked
+	if err := f.service.UpdateSession(sess); err != nil {
+		t.Fatalf("persisting hijacked subkey signature: %v", err)
+	}
+
+	req := &arcadeum.VerifyTimestampRequest{Timestamp: sess.Timestamp, Signature: hijacked}
+	payload, err := util.Jsonify(req)
+	if err != nil {
+		t.Fatalf("encoding SIGNED_TIMESTAMP payload: %v", err)
+	}
+	msg := &Message{
+		Meta:    &Meta{Code: SIGNED_TIMESTAMP, SubKey: sess.Player1.SubKey},
+		Payload: payload,
+	}
+
+	if err := f.service.OnMessage(msg); err == nil {
+		t.Fatal("expected OnMessage to reject a SIGNED_TIMESTAMP not derived from player.Account")
+	}
+
+	reloaded, err := f.service.GetSessionBySubKey(sess.Player1.SubKey)
+	if err != nil {
+		t.Fatalf("reloading session: %v", err)
+	}
+	if reloaded.Player1.Verified {
+		t.Fatal("session must not be mutated on a rejected timestamp proof")
+	}
+}
+
+// Scenario 5: InitGame racing a concurrent AddToMatchPool for the same
+// rank. InitGame is driven against a UUID that's already gone by the time
+// it looks the session up -- e.g. it lost the race to a cleanup that ran
+// between TakeSessionByRankAtIndex and InitGame -- while a second player's
+// AddToMatchPool runs on a real goroutine at the same time. ReaddToMatchPool
+// must restore the pool to exactly the seeded session plus the
+// concurrently added one: neither duplicated nor dropped by the overlap.
+func TestAdversarial_InitGameRaceRestoresPool(t *testing.T) {
+	f := newAdversarialFixture(t)
+
+	seeded, _ := newTestMatchResponse(t, 1)
+	seededSession, err := f.service.CreateSession(seeded)
+	if err != nil {
+		t.Fatalf("creating seeded session: %v", err)
+	}
+	if err := f.service.SessionManager.AddToMatchPool(seededSession); err != nil {
+		t.Fatalf("seeding waiting pool: %v", err)
+	}
+
+	// Take the seeded session off the pool the way Match() would, leaving
+	// its UUID the one InitGame is (about to be) handed.
+	taken, err := f.service.TakeSessionByRankAtIndex(1, 0)
+	if err != nil {
+		t.Fatalf("taking session from pool: %v", err)
+	}
+	if taken != seededSession.ID {
+		t.Fatalf("expected to take the seeded session %q, got %q", seededSession.ID, taken)
+	}
+
+	concurrent, _ := newTestMatchResponse(t, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	var initErr error
+	go func() {
+		defer wg.Done()
+		// Lost the race: by the time InitGame looks its target session up,
+		// it's gone (e.g. evicted by a cleanup that ran concurrently), so
+		// it must fail rather than pair a ghost session.
+		initErr = f.service.InitGame(UUID("session-already-gone"), seeded, beacon.BeaconEntry{})
+	}()
+	var addErr error
+	go func() {
+		defer wg.Done()
+		addErr = f.service.AddToMatchPool(concurrent)
+	}()
+	wg.Wait()
+
+	if initErr == nil {
+		t.Fatal("expected InitGame to fail when its target session no longer exists")
+	}
+	if addErr != nil {
+		t.Fatalf("concurrent AddToMatchPool failed: %v", addErr)
+	}
+
+	// Mirrors what Match() does when InitGame fails: restore the session it
+	// took off the pool.
+	f.service.ReaddToMatchPool(1, taken)
+
+	if size := f.service.PoolSizeByRank(1); size != 2 {
+		t.Fatalf("expected pool restored to the seeded session plus the concurrent add (2 waiting), got %d", size)
+	}
+}
+
+func toBytes32(b []byte) [32]byte {
+	var out [32]byte
+	copy(out[:], b)
+	return out
+}
@@ -0,0 +1,174 @@
+// Package audit maintains an append-only Merkle log of every
+// MatchVerifiedMessage the matcher has endorsed, so players and
+// watchtowers can later prove a match was - or was never - signed off,
+// instead of trusting BeginVerifiedMatch's publish-and-forget pub/sub
+// message.
+package audit
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+)
+
+// Domain separators per RFC 6962 section 2.1, preventing a leaf hash from
+// being reinterpreted as an inner node hash (the classic second-preimage
+// attack against naive Merkle trees).
+const (
+	leafPrefix  byte = 0x00
+	innerPrefix byte = 0x01
+)
+
+// LeafStore persists the append-only sequence of leaf hashes, plus a
+// side index from MatchHash to leaf index so a player who only knows
+// their match's MatchHash (not the leaf index the log happened to
+// assign it) can still ask for an inclusion proof. The matcher wires
+// this to Redis (see RedisLeafStore); tests can swap in an in-memory
+// implementation.
+type LeafStore interface {
+	// Append adds leaf as the next entry and returns its zero-based index.
+	Append(leaf [32]byte) (uint64, error)
+	// Leaves returns the leaf hashes in [from, to).
+	Leaves(from, to uint64) ([][32]byte, error)
+	// Count returns the number of leaves appended so far.
+	Count() (uint64, error)
+	// SetMatchIndex records that matchHash was appended at index.
+	SetMatchIndex(matchHash [32]byte, index uint64) error
+	// IndexForMatchHash returns the leaf index previously recorded for
+	// matchHash via SetMatchIndex, and whether one was found.
+	IndexForMatchHash(matchHash [32]byte) (uint64, bool, error)
+}
+
+// Log is an append-only Merkle tree of verified matches, rooted in
+// whatever LeafStore it's given.
+type Log struct {
+	store LeafStore
+}
+
+// NewLog wraps store as a Merkle log.
+func NewLog(store LeafStore) *Log {
+	return &Log{store: store}
+}
+
+// LeafHash hashes a canonically-encoded match into a leaf, domain
+// separated so it can never collide with an inner node hash.
+func LeafHash(data []byte) [32]byte {
+	return keccak(append([]byte{leafPrefix}, data...))
+}
+
+func innerHash(left, right [32]byte) [32]byte {
+	buf := make([]byte, 0, 1+64)
+	buf = append(buf, innerPrefix)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return keccak(buf)
+}
+
+func keccak(data []byte) [32]byte {
+	var out [32]byte
+	copy(out[:], crypto.Keccak256(data))
+	return out
+}
+
+// Append encodes msg canonically, hashes it as a leaf, and appends it to
+// the log, returning the leaf's index so a caller can later request an
+// inclusion proof for it. It also records msg.MatchHash -> index, so a
+// caller who only has the MatchHash (the value BeginVerifiedMatch hands
+// to players) can look up the index via IndexForMatchHash instead of
+// having to have recorded it out of band.
+func (l *Log) Append(msg *arcadeum.MatchVerifiedMessage) (uint64, error) {
+	leaf := LeafHash(CanonicalEncoding(msg))
+	index, err := l.store.Append(leaf)
+	if err != nil {
+		return 0, err
+	}
+	if err := l.store.SetMatchIndex(msg.MatchHash, index); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// IndexForMatchHash looks up the leaf index a prior Append assigned to
+// matchHash.
+func (l *Log) IndexForMatchHash(matchHash [32]byte) (uint64, bool, error) {
+	return l.store.IndexForMatchHash(matchHash)
+}
+
+// Root computes the current Merkle tree head and the number of leaves it
+// covers.
+func (l *Log) Root() ([32]byte, uint64, error) {
+	count, err := l.store.Count()
+	if err != nil {
+		return [32]byte{}, 0, err
+	}
+	if count == 0 {
+		return [32]byte{}, 0, nil
+	}
+	leaves, err := l.store.Leaves(0, count)
+	if err != nil {
+		return [32]byte{}, 0, err
+	}
+	return rootHash(leaves), count, nil
+}
+
+// rootHash implements the RFC 6962 Merkle Tree Hash over leaves, a
+// left-heavy binary tree split at the largest power of two less than
+// len(leaves), so the same definition works for tree sizes that aren't a
+// power of two.
+func rootHash(leaves [][32]byte) [32]byte {
+	n := len(leaves)
+	if n == 1 {
+		return leaves[0]
+	}
+	k := largestPowerOfTwoLessThan(n)
+	left := rootHash(leaves[:k])
+	right := rootHash(leaves[k:])
+	return innerHash(left, right)
+}
+
+// largestPowerOfTwoLessThan returns the largest power of two strictly less
+// than n, per RFC 6962's definition of k(n).
+func largestPowerOfTwoLessThan(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// CanonicalEncoding serializes the fields of a MatchVerifiedMessage that
+// define what the matcher endorsed: both accounts, both subkeys, the
+// match timestamp, both public seed hashes, both timestamp signatures,
+// and the matcher's own signature over the match hash.
+func CanonicalEncoding(msg *arcadeum.MatchVerifiedMessage) []byte {
+	var buf []byte
+	buf = append(buf, msg.Accounts[0].Bytes()...)
+	buf = append(buf, msg.Accounts[1].Bytes()...)
+	buf = append(buf, msg.Subkeys[0].Bytes()...)
+	buf = append(buf, msg.Subkeys[1].Bytes()...)
+
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(msg.Timestamp))
+	buf = append(buf, ts...)
+
+	for _, player := range msg.Players {
+		buf = append(buf, player.PublicSeed...)
+		buf = append(buf, encodeSignature(player.SignatureTimestamp)...)
+	}
+	buf = append(buf, encodeSignature(msg.SignatureMatchHash)...)
+
+	return buf
+}
+
+func encodeSignature(sig *cr.Signature) []byte {
+	if sig == nil {
+		return nil
+	}
+	buf := make([]byte, 0, 1+len(sig.R)+len(sig.S))
+	buf = append(buf, sig.V)
+	buf = append(buf, sig.R...)
+	buf = append(buf, sig.S...)
+	return buf
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// ChainPoster publishes a checkpoint root on-chain. The matcher satisfies
+// this with ArcadeumContract.PostMatchRoot, signed by the matcher's own
+// key via NewKeyedTransactor.
+type ChainPoster interface {
+	PostMatchRoot(root [32]byte, count uint64) error
+}
+
+// Checkpointer periodically posts the Merkle log's current root on-chain,
+// either after every LeafInterval new leaves or every TimeInterval,
+// whichever comes first.
+type Checkpointer struct {
+	Log          *Log
+	Poster       ChainPoster
+	LeafInterval uint64
+	TimeInterval time.Duration
+
+	mu               sync.Mutex
+	lastCheckpointed uint64
+}
+
+// NewCheckpointer builds a Checkpointer that posts every leafInterval new
+// leaves or every timeInterval, whichever comes first.
+func NewCheckpointer(l *Log, poster ChainPoster, leafInterval uint64, timeInterval time.Duration) *Checkpointer {
+	return &Checkpointer{
+		Log:          l,
+		Poster:       poster,
+		LeafInterval: leafInterval,
+		TimeInterval: timeInterval,
+	}
+}
+
+// Run blocks, checkpointing on a timer until stop is closed. If
+// TimeInterval is zero, Run only checkpoints in response to
+// MaybeCheckpointOnAppend's LeafInterval trigger -- it still blocks until
+// stop is closed, but never fires on its own.
+func (c *Checkpointer) Run(stop <-chan struct{}) {
+	if c.TimeInterval <= 0 {
+		<-stop
+		return
+	}
+	ticker := time.NewTicker(c.TimeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.maybeCheckpoint()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// MaybeCheckpointOnAppend should be called after every Log.Append; it posts
+// immediately once LeafInterval new leaves have accumulated rather than
+// waiting for the timer.
+func (c *Checkpointer) MaybeCheckpointOnAppend(count uint64) {
+	c.mu.Lock()
+	due := c.LeafInterval > 0 && count-c.lastCheckpointed >= c.LeafInterval
+	c.mu.Unlock()
+	if due {
+		c.maybeCheckpoint()
+	}
+}
+
+func (c *Checkpointer) maybeCheckpoint() {
+	root, count, err := c.Log.Root()
+	if err != nil {
+		log.Println("ERROR: audit: could not compute root for checkpoint", err)
+		return
+	}
+
+	c.mu.Lock()
+	if count == c.lastCheckpointed {
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	if err := c.Poster.PostMatchRoot(root, count); err != nil {
+		log.Println("ERROR: audit: could not post checkpoint root on-chain", err)
+		return
+	}
+
+	c.mu.Lock()
+	c.lastCheckpointed = count
+	c.mu.Unlock()
+}
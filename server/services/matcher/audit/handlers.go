@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/horizon-games/arcadeum/server/services/util"
+)
+
+type rootResponse struct {
+	Root  string `json:"root"`
+	Count uint64 `json:"count"`
+}
+
+// RootHandler serves the log's current Merkle root and leaf count.
+func (l *Log) RootHandler(w http.ResponseWriter, r *http.Request) {
+	root, count, err := l.Root()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, rootResponse{Root: hex.EncodeToString(root[:]), Count: count})
+}
+
+type inclusionProofResponse struct {
+	LeafIndex uint64   `json:"leafIndex"`
+	TreeSize  uint64   `json:"treeSize"`
+	Path      []string `json:"path"`
+}
+
+// InclusionProofHandler serves the audit path proving the leaf at the
+// "index" query parameter -- or, if "index" is omitted, the leaf
+// previously recorded for the "matchHash" query parameter -- is included
+// in the tree at "size" (defaulting to the current tree size).
+func (l *Log) InclusionProofHandler(w http.ResponseWriter, r *http.Request) {
+	index, err := l.resolveIndex(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	size, err := parseSizeOrCurrent(r, l)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	path, err := l.InclusionProof(index, size)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, inclusionProofResponse{LeafIndex: index, TreeSize: size, Path: hexEncodeAll(path)})
+}
+
+type consistencyProofResponse struct {
+	From uint64   `json:"from"`
+	To   uint64   `json:"to"`
+	Path []string `json:"path"`
+}
+
+// ConsistencyProofHandler serves the RFC 6962 consistency proof between
+// the tree sizes given by the "from" and "to" query parameters.
+func (l *Log) ConsistencyProofHandler(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid or missing from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseSizeOrCurrent(r, l)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	path, err := l.ConsistencyProof(from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, consistencyProofResponse{From: from, To: to, Path: hexEncodeAll(path)})
+}
+
+// resolveIndex reads the leaf index to prove inclusion of, from either
+// the "index" query parameter directly or, if that's absent, a
+// "matchHash" parameter looked up via IndexForMatchHash.
+func (l *Log) resolveIndex(r *http.Request) (uint64, error) {
+	if raw := r.URL.Query().Get("index"); raw != "" {
+		return strconv.ParseUint(raw, 10, 64)
+	}
+	raw := r.URL.Query().Get("matchHash")
+	if raw == "" {
+		return 0, errMissingIndexOrMatchHash
+	}
+	decoded, err := hex.DecodeString(raw)
+	if err != nil || len(decoded) != 32 {
+		return 0, errMissingIndexOrMatchHash
+	}
+	var matchHash [32]byte
+	copy(matchHash[:], decoded)
+	index, ok, err := l.IndexForMatchHash(matchHash)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("audit: no leaf recorded for match hash %s", raw)
+	}
+	return index, nil
+}
+
+var errMissingIndexOrMatchHash = fmt.Errorf("invalid or missing index or matchHash")
+
+func parseSizeOrCurrent(r *http.Request, l *Log) (uint64, error) {
+	raw := r.URL.Query().Get("to")
+	if raw == "" {
+		raw = r.URL.Query().Get("size")
+	}
+	if raw == "" {
+		_, count, err := l.Root()
+		return count, err
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+func hexEncodeAll(path [][32]byte) []string {
+	out := make([]string, len(path))
+	for i, p := range path {
+		out[i] = hex.EncodeToString(p[:])
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	payload, err := util.Jsonify(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(payload))
+}
@@ -0,0 +1,106 @@
+package audit
+
+import "fmt"
+
+// InclusionProof returns the RFC 6962 audit path proving that the leaf at
+// index is included in the tree of the given size (the tree size at the
+// time the proof is requested against, which may be later than when the
+// leaf was appended).
+func (l *Log) InclusionProof(index, size uint64) ([][32]byte, error) {
+	if index >= size {
+		return nil, fmt.Errorf("audit: leaf index %d out of range for tree size %d", index, size)
+	}
+	leaves, err := l.store.Leaves(0, size)
+	if err != nil {
+		return nil, err
+	}
+	return auditPath(leaves, int(index), 0, len(leaves)), nil
+}
+
+// auditPath recursively computes PATH(index, leaves) from RFC 6962 2.1.1:
+// the sibling hash at each level from the leaf up to the root.
+func auditPath(leaves [][32]byte, index, lo, hi int) [][32]byte {
+	n := hi - lo
+	if n <= 1 {
+		return nil
+	}
+	k := largestPowerOfTwoLessThan(n)
+	if index-lo < k {
+		path := auditPath(leaves, index, lo, lo+k)
+		sibling := rootHash(leaves[lo+k : hi])
+		return append(path, sibling)
+	}
+	path := auditPath(leaves, index, lo+k, hi)
+	sibling := rootHash(leaves[lo : lo+k])
+	return append(path, sibling)
+}
+
+// VerifyInclusion recomputes the root from leaf, its index, the tree size,
+// and an audit path, and reports whether it matches root.
+func VerifyInclusion(leaf [32]byte, index, size uint64, path [][32]byte, root [32]byte) bool {
+	computed, ok := computeRootFromPath(leaf, int(index), int(size), path)
+	return ok && computed == root
+}
+
+// computeRootFromPath folds path, ordered leaf-up as auditPath returns it,
+// into a root. The side of each split is found root-down, so it's applied
+// in reverse.
+func computeRootFromPath(leaf [32]byte, index, size int, path [][32]byte) ([32]byte, bool) {
+	var leftSide []bool
+	lo, hi := 0, size
+	for hi-lo > 1 {
+		k := largestPowerOfTwoLessThan(hi - lo)
+		leftSide = append(leftSide, index-lo < k)
+		if index-lo < k {
+			hi = lo + k
+		} else {
+			lo = lo + k
+		}
+	}
+	if len(leftSide) != len(path) {
+		return [32]byte{}, false
+	}
+	node := leaf
+	for i, sibling := range path {
+		if leftSide[len(path)-1-i] {
+			node = innerHash(node, sibling)
+		} else {
+			node = innerHash(sibling, node)
+		}
+	}
+	return node, true
+}
+
+// ConsistencyProof returns the RFC 6962 2.1.2 proof that the tree at size
+// "to" is an append-only extension of the tree at size "from".
+func (l *Log) ConsistencyProof(from, to uint64) ([][32]byte, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("audit: invalid consistency range [%d, %d)", from, to)
+	}
+	leaves, err := l.store.Leaves(0, to)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, nil
+	}
+	return subProof(leaves, int(from), 0, len(leaves), true), nil
+}
+
+// subProof implements RFC 6962's SUBPROOF(m, D[lo:hi], b).
+func subProof(leaves [][32]byte, m, lo, hi int, complete bool) [][32]byte {
+	n := hi - lo
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][32]byte{rootHash(leaves[lo:hi])}
+	}
+	k := largestPowerOfTwoLessThan(n)
+	if m <= k {
+		path := subProof(leaves, m, lo, lo+k, complete)
+		return append(path, rootHash(leaves[lo+k:hi]))
+	}
+	path := subProof(leaves, m-k, lo+k, hi, false)
+	return append(path, rootHash(leaves[lo:lo+k]))
+}
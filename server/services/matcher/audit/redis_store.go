@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"encoding/hex"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/horizon-games/arcadeum/server/config"
+)
+
+// RedisLeafStore stores leaf hashes as an append-only Redis list, so the
+// log survives matcher restarts the same way session state already does.
+// The match-hash -> index side index lives in a Redis hash keyed off the
+// same key with a ":matchIndex" suffix.
+type RedisLeafStore struct {
+	pool          *redis.Pool
+	key           string
+	matchIndexKey string
+}
+
+// NewRedisLeafStore dials cfg and stores leaves under key (e.g.
+// "audit:leaves").
+func NewRedisLeafStore(cfg *config.RedisConfig, key string) *RedisLeafStore {
+	return &RedisLeafStore{
+		pool: &redis.Pool{
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", cfg.Address, redis.DialPassword(cfg.Password))
+			},
+		},
+		key:           key,
+		matchIndexKey: key + ":matchIndex",
+	}
+}
+
+func (s *RedisLeafStore) Append(leaf [32]byte) (uint64, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	count, err := redis.Int64(conn.Do("RPUSH", s.key, leaf[:]))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(count - 1), nil
+}
+
+func (s *RedisLeafStore) Leaves(from, to uint64) ([][32]byte, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	raw, err := redis.ByteSlices(conn.Do("LRANGE", s.key, from, int64(to)-1))
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, len(raw))
+	for i, b := range raw {
+		copy(leaves[i][:], b)
+	}
+	return leaves, nil
+}
+
+func (s *RedisLeafStore) Count() (uint64, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	count, err := redis.Int64(conn.Do("LLEN", s.key))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+func (s *RedisLeafStore) SetMatchIndex(matchHash [32]byte, index uint64) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HSET", s.matchIndexKey, hex.EncodeToString(matchHash[:]), index)
+	return err
+}
+
+func (s *RedisLeafStore) IndexForMatchHash(matchHash [32]byte) (uint64, bool, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	index, err := redis.Uint64(conn.Do("HGET", s.matchIndexKey, hex.EncodeToString(matchHash[:])))
+	if err == redis.ErrNil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return index, true, nil
+}
@@ -0,0 +1,18 @@
+package matcher
+
+// chainCheckpointPoster adapts ArcadeumContract.PostMatchRoot to
+// audit.ChainPoster, signing the checkpoint transaction with the
+// matcher's own key just like StopWithdrawalXXX does.
+type chainCheckpointPoster struct {
+	service *Service
+}
+
+func (p *chainCheckpointPoster) PostMatchRoot(root [32]byte, count uint64) error {
+	opts := p.service.NewKeyedTransactor()
+	opts.From = p.service.Config.AccountAddress
+	opts.Value = nil
+	opts.GasLimit = 0
+	opts.GasPrice = nil
+	_, err := p.service.ArcClient.ArcadeumContract.PostMatchRoot(opts, root, count)
+	return err
+}
@@ -0,0 +1,244 @@
+// Package beacon provides a client for drand-style randomness beacons,
+// used by the matcher to pick opponents in a way that players can verify
+// after the fact was not steered by the matcher operator.
+package beacon
+
+import (
+	"container/list"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BeaconEntry is a single round of a drand randomness chain.
+type BeaconEntry struct {
+	Round             uint64 `json:"round"`
+	Randomness        []byte `json:"randomness"`
+	Signature         []byte `json:"signature"`
+	PreviousSignature []byte `json:"previous_signature"`
+}
+
+// BeaconAPI is the interface the matcher depends on for verifiable
+// randomness. The HTTP-backed implementation below talks to a drand relay;
+// tests and local development can supply a fake implementation instead.
+type BeaconAPI interface {
+	// Entry fetches (and verifies, where possible) the beacon entry for round.
+	Entry(ctx context.Context, round uint64) (BeaconEntry, error)
+
+	// VerifyEntry checks that cur is a valid successor of prev according to
+	// the chain's signature scheme.
+	VerifyEntry(prev, cur BeaconEntry) error
+
+	// LatestBeaconRound returns the most recently published round number.
+	LatestBeaconRound(ctx context.Context) (uint64, error)
+}
+
+// ErrUnverifiedEntry is returned when a fetched entry fails verification
+// against the chain's public key or its predecessor.
+var ErrUnverifiedEntry = errors.New("beacon: entry failed verification")
+
+// HTTPClient is a BeaconAPI backed by a drand HTTP relay, e.g. one of the
+// public gateways operated by league-of-entropy members.
+type HTTPClient struct {
+	BaseURL    string // e.g. "https://drand.cloudflare.com/<chain-hash>"
+	ChainHash  string
+	PublicKey  []byte // chain's distributed public key, for signature checks
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	cache *lru
+}
+
+// NewHTTPClient constructs a drand HTTP client bounded to chainHash, caching
+// up to cacheSize recently seen entries.
+func NewHTTPClient(baseURL, chainHash string, publicKey []byte, cacheSize int) *HTTPClient {
+	return &HTTPClient{
+		BaseURL:    baseURL,
+		ChainHash:  chainHash,
+		PublicKey:  publicKey,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		cache:      newLRU(cacheSize),
+	}
+}
+
+type httpEntry struct {
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness"`
+	Signature         string `json:"signature"`
+	PreviousSignature string `json:"previous_signature"`
+}
+
+func (c *HTTPClient) Entry(ctx context.Context, round uint64) (BeaconEntry, error) {
+	if e, ok := c.get(round); ok {
+		return e, nil
+	}
+
+	path := fmt.Sprintf("%s/public/latest", c.BaseURL)
+	if round != 0 {
+		path = fmt.Sprintf("%s/public/%d", c.BaseURL, round)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return BeaconEntry{}, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return BeaconEntry{}, fmt.Errorf("beacon: fetching round %d: %w", round, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return BeaconEntry{}, fmt.Errorf("beacon: round %d returned status %d", round, resp.StatusCode)
+	}
+
+	var raw httpEntry
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return BeaconEntry{}, err
+	}
+
+	entry, err := decodeEntry(raw)
+	if err != nil {
+		return BeaconEntry{}, err
+	}
+
+	if err := c.verify(ctx, entry); err != nil {
+		return BeaconEntry{}, err
+	}
+
+	c.put(entry)
+	return entry, nil
+}
+
+// verify checks entry against its predecessor, fetching it (recursively
+// verifying it in turn) if it isn't already cached. Nothing polls rounds
+// in sequence -- Match() fetches only the latest round, when a player
+// arrives -- so this walks back one request per round until it reaches a
+// cached round, or round 1 on a cold cache.
+func (c *HTTPClient) verify(ctx context.Context, entry BeaconEntry) error {
+	var prev BeaconEntry
+	if entry.Round > 1 {
+		var err error
+		prev, err = c.Entry(ctx, entry.Round-1)
+		if err != nil {
+			return fmt.Errorf("beacon: fetching predecessor round %d: %w", entry.Round-1, err)
+		}
+	}
+	return c.VerifyEntry(prev, entry)
+}
+
+func decodeEntry(raw httpEntry) (BeaconEntry, error) {
+	randomness, err := hex.DecodeString(raw.Randomness)
+	if err != nil {
+		return BeaconEntry{}, fmt.Errorf("beacon: decoding randomness: %w", err)
+	}
+	signature, err := hex.DecodeString(raw.Signature)
+	if err != nil {
+		return BeaconEntry{}, fmt.Errorf("beacon: decoding signature: %w", err)
+	}
+	var previous []byte
+	if raw.PreviousSignature != "" {
+		previous, err = hex.DecodeString(raw.PreviousSignature)
+		if err != nil {
+			return BeaconEntry{}, fmt.Errorf("beacon: decoding previous signature: %w", err)
+		}
+	}
+	return BeaconEntry{
+		Round:             raw.Round,
+		Randomness:        randomness,
+		Signature:         signature,
+		PreviousSignature: previous,
+	}, nil
+}
+
+// VerifyEntry checks that cur chains from prev. Quicknet-style chains (no
+// previous signature, BLS unchained) only need their own signature checked
+// against the chain public key; classic drand chains additionally require
+// cur.PreviousSignature == prev.Signature.
+func (c *HTTPClient) VerifyEntry(prev, cur BeaconEntry) error {
+	if len(cur.PreviousSignature) > 0 {
+		if prev.Round == 0 || string(prev.Signature) != string(cur.PreviousSignature) {
+			return ErrUnverifiedEntry
+		}
+	}
+	if len(cur.Signature) == 0 || len(c.PublicKey) == 0 {
+		return ErrUnverifiedEntry
+	}
+	return verifyBLSSignature(c.PublicKey, cur)
+}
+
+func (c *HTTPClient) LatestBeaconRound(ctx context.Context) (uint64, error) {
+	entry, err := c.Entry(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	return entry.Round, nil
+}
+
+func (c *HTTPClient) get(round uint64) (BeaconEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.get(round)
+}
+
+func (c *HTTPClient) put(entry BeaconEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.put(entry)
+}
+
+// lru is a small fixed-capacity cache of recently fetched beacon entries,
+// keyed by round, so a round already fetched and verified (such as the
+// predecessor verify() checks a new entry against) isn't fetched again.
+// The latest round, requested as round 0, is never served from here: every
+// Match() still fetches it from the relay.
+type lru struct {
+	capacity int
+	ll       *list.List
+	items    map[uint64]*list.Element
+}
+
+type lruItem struct {
+	round uint64
+	entry BeaconEntry
+}
+
+func newLRU(capacity int) *lru {
+	if capacity <= 0 {
+		capacity = 32
+	}
+	return &lru{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[uint64]*list.Element, capacity),
+	}
+}
+
+func (c *lru) get(round uint64) (BeaconEntry, bool) {
+	el, ok := c.items[round]
+	if !ok {
+		return BeaconEntry{}, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (c *lru) put(entry BeaconEntry) {
+	if el, ok := c.items[entry.Round]; ok {
+		el.Value.(*lruItem).entry = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+	el := c.ll.PushFront(&lruItem{round: entry.Round, entry: entry})
+	c.items[entry.Round] = el
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruItem).round)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// blsSigDST is the hash-to-curve domain separation tag drand's BLS12-381
+// chains sign under: a G1 signature, G2 public key scheme per the IETF
+// BLS signature draft (draft-irtf-cfrg-bls-signature), "minimal-signature-size"
+// variant.
+const blsSigDST = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"
+
+var g2Generator bls12381.G2Affine
+
+func init() {
+	_, _, _, g2Generator = bls12381.Generators()
+}
+
+// digestMessage reproduces drand's round digest: sha256(round || prevSig)
+// for a classic chained beacon, or sha256(round) alone for an unchained
+// one like quicknet, where cur.PreviousSignature is always empty.
+func digestMessage(cur BeaconEntry) []byte {
+	roundBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(roundBytes, cur.Round)
+	h := sha256.New()
+	h.Write(roundBytes)
+	h.Write(cur.PreviousSignature)
+	return h.Sum(nil)
+}
+
+// verifyBLSSignature checks cur.Signature against pubkey using drand's
+// actual signing curve and scheme: a compressed G1 point signs
+// digestMessage(cur), hashed to G1 per the IETF BLS draft, and verifies
+// against a G2 public key via e(sig, g2) == e(H(m), pubkey).
+func verifyBLSSignature(pubkey []byte, cur BeaconEntry) error {
+	var sig bls12381.G1Affine
+	if err := sig.Unmarshal(cur.Signature); err != nil {
+		return ErrUnverifiedEntry
+	}
+	var pub bls12381.G2Affine
+	if err := pub.Unmarshal(pubkey); err != nil {
+		return ErrUnverifiedEntry
+	}
+
+	hm, err := bls12381.HashToG1(digestMessage(cur), []byte(blsSigDST))
+	if err != nil {
+		return ErrUnverifiedEntry
+	}
+
+	// e(sig, g2Generator) == e(hm, pub)  <=>  e(sig, g2Generator) * e(-hm, pub) == 1
+	negHM := new(bls12381.G1Affine).Neg(&hm)
+	ok, err := bls12381.PairingCheck(
+		[]bls12381.G1Affine{sig, *negHM},
+		[]bls12381.G2Affine{g2Generator, pub},
+	)
+	if err != nil || !ok {
+		return ErrUnverifiedEntry
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package beacon
+
+import "fmt"
+
+// BeaconNetworks indexes the beacon chains the matcher knows how to read
+// from, keyed by chain hash, plus the round at which a given chain becomes
+// the source of truth. This mirrors arcadeum.Client's GameAddress map and
+// lets the matcher migrate between drand chains (e.g. the classic "main"
+// chain and the faster "quicknet") without losing the ability to verify
+// randomness used in historical matches.
+type BeaconNetworks struct {
+	// networks holds every chain this matcher can read, keyed by chain hash.
+	networks map[string]BeaconAPI
+
+	// cutovers lists, in ascending round order, the round at which the
+	// named chain hash took over as the active source of randomness.
+	cutovers []networkCutover
+}
+
+type networkCutover struct {
+	fromRound uint64
+	chainHash string
+}
+
+// NewBeaconNetworks builds a registry from the active set of chains. active
+// must be ordered oldest-first; the last entry is treated as the current
+// chain for new matches.
+func NewBeaconNetworks(active []struct {
+	ChainHash string
+	FromRound uint64
+	Client    BeaconAPI
+}) *BeaconNetworks {
+	n := &BeaconNetworks{networks: make(map[string]BeaconAPI, len(active))}
+	for _, a := range active {
+		n.networks[a.ChainHash] = a.Client
+		n.cutovers = append(n.cutovers, networkCutover{fromRound: a.FromRound, chainHash: a.ChainHash})
+	}
+	return n
+}
+
+// NetworkConfig is the subset of config.MatcherConfig.BeaconNetworks needed
+// to dial a drand chain: its HTTP relay, chain hash, distributed public
+// key, and the round it takes over as the active chain.
+type NetworkConfig struct {
+	Endpoint  string
+	ChainHash string
+	PublicKey []byte
+	FromRound uint64
+	CacheSize int
+}
+
+// NewBeaconNetworksFromConfig builds a registry from configuration,
+// dialing an HTTPClient per entry. Entries should be ordered oldest-first.
+func NewBeaconNetworksFromConfig(cfgs []NetworkConfig) *BeaconNetworks {
+	n := &BeaconNetworks{networks: make(map[string]BeaconAPI, len(cfgs))}
+	for _, c := range cfgs {
+		client := NewHTTPClient(c.Endpoint, c.ChainHash, c.PublicKey, c.CacheSize)
+		n.Register(c.ChainHash, c.FromRound, client)
+	}
+	return n
+}
+
+// Register adds or replaces the client for chainHash, becoming the active
+// chain for any round >= fromRound.
+func (n *BeaconNetworks) Register(chainHash string, fromRound uint64, client BeaconAPI) {
+	n.networks[chainHash] = client
+	n.cutovers = append(n.cutovers, networkCutover{fromRound: fromRound, chainHash: chainHash})
+}
+
+// BeaconNetworkForRound returns the beacon client responsible for round,
+// i.e. the chain with the highest fromRound not exceeding round. This lets
+// a match selected under an old chain still be independently verified
+// after the matcher has migrated to a newer one.
+func (n *BeaconNetworks) BeaconNetworkForRound(round uint64) (BeaconAPI, error) {
+	var best *networkCutover
+	for i := range n.cutovers {
+		c := n.cutovers[i]
+		if c.fromRound > round {
+			continue
+		}
+		if best == nil || c.fromRound > best.fromRound {
+			best = &n.cutovers[i]
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("beacon: no registered chain covers round %d", round)
+	}
+	client, ok := n.networks[best.chainHash]
+	if !ok {
+		return nil, fmt.Errorf("beacon: chain %s not registered", best.chainHash)
+	}
+	return client, nil
+}
+
+// Active returns the client for the chain currently accepting new rounds,
+// i.e. the one with the highest fromRound.
+func (n *BeaconNetworks) Active() (BeaconAPI, error) {
+	var best *networkCutover
+	for i := range n.cutovers {
+		if best == nil || n.cutovers[i].fromRound > best.fromRound {
+			best = &n.cutovers[i]
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("beacon: no chains registered")
+	}
+	return n.networks[best.chainHash], nil
+}
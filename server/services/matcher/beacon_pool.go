@@ -0,0 +1,25 @@
+package matcher
+
+import "log"
+
+// PoolSizeByRank and TakeSessionByRankAtIndex expose the waiting pool for a
+// given rank by position rather than handing back an arbitrary member, so
+// BeaconPoolIndex can pick a slot deterministically from beacon randomness
+// instead of trusting the matcher to choose fairly.
+
+// PoolSizeByRank returns the number of sessions currently waiting at rank.
+func (s *Service) PoolSizeByRank(rank uint32) int {
+	size, err := s.SessionManager.WaitingPoolSize(rank)
+	if err != nil {
+		log.Println("ERROR: could not read waiting pool size", err)
+		return 0
+	}
+	return size
+}
+
+// TakeSessionByRankAtIndex dequeues the session at position index (mod pool
+// size) from the waiting pool for rank. It returns an empty UUID when the
+// pool has nothing waiting.
+func (s *Service) TakeSessionByRankAtIndex(rank uint32, index int) (UUID, error) {
+	return s.SessionManager.TakeSessionByRankAtIndex(rank, index)
+}
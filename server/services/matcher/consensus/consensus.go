@@ -0,0 +1,416 @@
+// Package consensus lets a quorum of matcher nodes co-sign a
+// MatchVerifiedMessage via a PBFT-style three-phase protocol, so no single
+// matcher operator can unilaterally mint a valid match.
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+)
+
+// Phase identifies which round of the three-phase protocol a message
+// belongs to.
+type Phase int
+
+const (
+	PrePrepare Phase = iota
+	Prepare
+	Commit
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PrePrepare:
+		return "pre-prepare"
+	case Prepare:
+		return "prepare"
+	case Commit:
+		return "commit"
+	default:
+		return "unknown"
+	}
+}
+
+// CandidateValidator re-runs the checks a single matcher would have done
+// before signing: the two timestamp signatures and the on-chain stake
+// status.
+type CandidateValidator func(candidate *arcadeum.MatchVerifiedMessage) error
+
+// Transport is the minimal pub/sub surface consensus needs. The matcher
+// can satisfy this with its existing PubSubManager (over Redis) or with a
+// libp2p pubsub topic; the protocol itself doesn't care.
+type Transport interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(topic string, handler func(payload []byte)) error
+}
+
+// ConsensusMessage is exchanged between matcher nodes during all three
+// phases. Candidate is only populated on PrePrepare; later phases refer to
+// the proposal by MatchHash alone.
+type ConsensusMessage struct {
+	Phase     Phase                           `json:"phase"`
+	View      uint64                          `json:"view"`
+	MatchHash [32]byte                        `json:"matchHash"`
+	Candidate *arcadeum.MatchVerifiedMessage  `json:"candidate,omitempty"`
+	NodeID    string                          `json:"nodeId"`
+	Signature *cr.Signature                   `json:"signature"`
+}
+
+// F is the maximum number of faulty/byzantine nodes the quorum tolerates.
+// A quorum of 2F+1 matching Prepare (or Commit) messages is required to
+// advance a phase.
+type Node struct {
+	ID        string
+	PrivKey   *ecdsa.PrivateKey
+	Peers     []string // node IDs of all participants, including self
+	F         int
+	Topic     string
+	Transport Transport
+	Validate  CandidateValidator
+
+	// OnCommitted is invoked once 2F+1 Commit messages have been gathered
+	// for a proposal, with the aggregated signature set in peer order.
+	OnCommitted func(candidate *arcadeum.MatchVerifiedMessage, signatures []*cr.Signature)
+
+	mu        sync.Mutex
+	proposal  map[[32]byte]*arcadeum.MatchVerifiedMessage
+	prepares  map[[32]byte]map[string]*cr.Signature
+	commits   map[[32]byte]map[string]*cr.Signature
+	prepared  map[[32]byte]bool
+	committed map[[32]byte]bool
+	waiters   map[[32]byte][]chan []*cr.Signature
+	view      uint64
+}
+
+// NewNode constructs a PBFT participant. peers should list every node ID in
+// the quorum, including this node's own ID.
+func NewNode(id string, privkey *ecdsa.PrivateKey, peers []string, topic string, transport Transport, validate CandidateValidator) *Node {
+	n := &Node{
+		ID:        id,
+		PrivKey:   privkey,
+		Peers:     peers,
+		F:         (len(peers) - 1) / 3,
+		Topic:     topic,
+		Transport: transport,
+		Validate:  validate,
+		proposal:  make(map[[32]byte]*arcadeum.MatchVerifiedMessage),
+		prepares:  make(map[[32]byte]map[string]*cr.Signature),
+		commits:   make(map[[32]byte]map[string]*cr.Signature),
+		prepared:  make(map[[32]byte]bool),
+		committed: make(map[[32]byte]bool),
+		waiters:   make(map[[32]byte][]chan []*cr.Signature),
+	}
+	transport.Subscribe(topic, n.onWireMessage)
+	return n
+}
+
+// quorum returns the number of matching phase messages required to advance,
+// i.e. 2F+1.
+func (n *Node) quorum() int {
+	return 2*n.F + 1
+}
+
+// ProposeMatch broadcasts a PrePrepare for candidate. Only the node acting
+// as proposer for the current view should call this.
+func (n *Node) ProposeMatch(candidate *arcadeum.MatchVerifiedMessage) error {
+	if err := n.Validate(candidate); err != nil {
+		return fmt.Errorf("consensus: refusing to propose invalid candidate: %w", err)
+	}
+	msg := ConsensusMessage{
+		Phase:     PrePrepare,
+		View:      n.currentView(),
+		MatchHash: candidate.MatchHash,
+		Candidate: candidate,
+		NodeID:    n.ID,
+	}
+	sig, err := n.sign(msg.MatchHash)
+	if err != nil {
+		return err
+	}
+	msg.Signature = sig
+	return n.broadcast(msg)
+}
+
+func (n *Node) currentView() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.view
+}
+
+func (n *Node) onWireMessage(payload []byte) {
+	msg, err := decode(payload)
+	if err != nil {
+		log.Println("ERROR: consensus: could not decode message", err)
+		return
+	}
+	if err := n.OnMessage(msg); err != nil {
+		log.Printf("ERROR: consensus: %s phase from %s rejected: %s", msg.Phase, msg.NodeID, err.Error())
+	}
+}
+
+// OnMessage drives the PBFT state machine. It is exported so tests (and
+// alternate transports) can feed messages directly.
+func (n *Node) OnMessage(msg ConsensusMessage) error {
+	switch msg.Phase {
+	case PrePrepare:
+		return n.onPrePrepare(msg)
+	case Prepare:
+		return n.onPrepare(msg)
+	case Commit:
+		return n.onCommit(msg)
+	default:
+		return errors.New("consensus: unknown phase")
+	}
+}
+
+func (n *Node) onPrePrepare(msg ConsensusMessage) error {
+	if msg.Candidate == nil {
+		return errors.New("consensus: pre-prepare missing candidate")
+	}
+	if err := n.Validate(msg.Candidate); err != nil {
+		return fmt.Errorf("candidate failed validation: %w", err)
+	}
+	if msg.Candidate.MatchHash != msg.MatchHash {
+		return errors.New("consensus: candidate hash mismatch")
+	}
+
+	n.mu.Lock()
+	n.proposal[msg.MatchHash] = msg.Candidate
+	n.mu.Unlock()
+
+	prepare := ConsensusMessage{
+		Phase:     Prepare,
+		View:      msg.View,
+		MatchHash: msg.MatchHash,
+		NodeID:    n.ID,
+	}
+	sig, err := n.sign(prepare.MatchHash)
+	if err != nil {
+		return err
+	}
+	prepare.Signature = sig
+	return n.broadcast(prepare)
+}
+
+func (n *Node) onPrepare(msg ConsensusMessage) error {
+	if err := n.recordVote(n.prepares, msg); err != nil {
+		return err
+	}
+	if n.tally(n.prepares, msg.MatchHash) < n.quorum() {
+		return nil
+	}
+	if !n.markAdvanced(n.prepared, msg.MatchHash) {
+		return nil
+	}
+
+	commit := ConsensusMessage{
+		Phase:     Commit,
+		View:      msg.View,
+		MatchHash: msg.MatchHash,
+		NodeID:    n.ID,
+	}
+	sig, err := n.sign(commit.MatchHash)
+	if err != nil {
+		return err
+	}
+	commit.Signature = sig
+	return n.broadcast(commit)
+}
+
+func (n *Node) onCommit(msg ConsensusMessage) error {
+	if err := n.recordVote(n.commits, msg); err != nil {
+		return err
+	}
+	if n.tally(n.commits, msg.MatchHash) < n.quorum() {
+		return nil
+	}
+	if !n.markAdvanced(n.committed, msg.MatchHash) {
+		return nil
+	}
+
+	n.mu.Lock()
+	candidate, ok := n.proposal[msg.MatchHash]
+	sigs := n.commits[msg.MatchHash]
+	n.mu.Unlock()
+	if !ok {
+		return errors.New("consensus: committed to unknown proposal")
+	}
+
+	aggregated := aggregate(n.Peers, sigs)
+	if n.OnCommitted != nil {
+		n.OnCommitted(candidate, aggregated)
+	}
+	n.notifyWaiters(msg.MatchHash, aggregated)
+	return nil
+}
+
+// markAdvanced reports whether hash has not yet been recorded in stage --
+// recording it if so -- so onPrepare/onCommit re-broadcast a Commit or
+// fire OnCommitted/notifyWaiters exactly once per match hash, instead of
+// once per vote that arrives at or past quorum.
+func (n *Node) markAdvanced(stage map[[32]byte]bool, hash [32]byte) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if stage[hash] {
+		return false
+	}
+	stage[hash] = true
+	return true
+}
+
+// addWaiter registers a one-shot channel to be sent the commit signature
+// set for hash, for ProposeAndAwaitCommit to block on without mutating the
+// shared OnCommitted field (which races if multiple proposals are in
+// flight on the same node at once).
+func (n *Node) addWaiter(hash [32]byte) chan []*cr.Signature {
+	ch := make(chan []*cr.Signature, 1)
+	n.mu.Lock()
+	n.waiters[hash] = append(n.waiters[hash], ch)
+	n.mu.Unlock()
+	return ch
+}
+
+// removeWaiter unregisters ch, e.g. after a timeout so onCommit doesn't
+// send to a channel nobody is reading anymore.
+func (n *Node) removeWaiter(hash [32]byte, ch chan []*cr.Signature) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	waiters := n.waiters[hash]
+	for i, w := range waiters {
+		if w == ch {
+			n.waiters[hash] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(n.waiters[hash]) == 0 {
+		delete(n.waiters, hash)
+	}
+}
+
+// notifyWaiters sends sigs to every waiter registered for hash.
+func (n *Node) notifyWaiters(hash [32]byte, sigs []*cr.Signature) {
+	n.mu.Lock()
+	waiters := n.waiters[hash]
+	delete(n.waiters, hash)
+	n.mu.Unlock()
+	for _, ch := range waiters {
+		ch <- sigs
+	}
+}
+
+func (n *Node) recordVote(votes map[[32]byte]map[string]*cr.Signature, msg ConsensusMessage) error {
+	if msg.Signature == nil {
+		return errors.New("consensus: vote missing signature")
+	}
+	if !n.isPeer(msg.NodeID) {
+		return fmt.Errorf("consensus: vote from unknown peer %s", msg.NodeID)
+	}
+	if err := n.verifyVote(msg); err != nil {
+		return fmt.Errorf("consensus: vote from %s failed signature check: %w", msg.NodeID, err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	byHash, ok := votes[msg.MatchHash]
+	if !ok {
+		byHash = make(map[string]*cr.Signature)
+		votes[msg.MatchHash] = byHash
+	}
+	byHash[msg.NodeID] = msg.Signature
+	return nil
+}
+
+// isPeer reports whether id names one of this node's configured peers.
+// Node IDs are the peer's Ethereum address (hex), the same identity
+// verifyVote recovers msg.Signature against.
+func (n *Node) isPeer(id string) bool {
+	for _, peer := range n.Peers {
+		if peer == id {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyVote checks that msg.Signature is msg.NodeID's own signature over
+// msg.MatchHash, so a byzantine node can't stuff the quorum by forging
+// votes on another peer's behalf.
+func (n *Node) verifyVote(msg ConsensusMessage) error {
+	sig := msg.Signature
+	sigBytes := make([]byte, 65)
+	copy(sigBytes[0:32], sig.R)
+	copy(sigBytes[32:64], sig.S)
+	sigBytes[64] = sig.V - 27
+	pub, err := crypto.SigToPub(msg.MatchHash[:], sigBytes)
+	if err != nil {
+		return err
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != common.HexToAddress(msg.NodeID) {
+		return fmt.Errorf("signature recovers to %s, not claimed node %s", signer, msg.NodeID)
+	}
+	return nil
+}
+
+func (n *Node) tally(votes map[[32]byte]map[string]*cr.Signature, hash [32]byte) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(votes[hash])
+}
+
+// aggregate lays out commit signatures in a stable, peer-order sequence so
+// every honest node produces the same SignatureSet for the same proposal.
+func aggregate(peers []string, votes map[string]*cr.Signature) []*cr.Signature {
+	sigs := make([]*cr.Signature, 0, len(votes))
+	for _, id := range peers {
+		if sig, ok := votes[id]; ok {
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs
+}
+
+func (n *Node) sign(hash [32]byte) (*cr.Signature, error) {
+	sig, err := crypto.Sign(hash[:], n.PrivKey)
+	if err != nil {
+		return nil, err
+	}
+	return &cr.Signature{
+		V: 27 + sig[64],
+		R: sig[0:32],
+		S: sig[32:64],
+	}, nil
+}
+
+func (n *Node) broadcast(msg ConsensusMessage) error {
+	payload, err := encode(msg)
+	if err != nil {
+		return err
+	}
+	return n.Transport.Publish(n.Topic, payload)
+}
+
+// TriggerViewChange advances the view after a proposer timeout, so a new
+// node takes over as proposer. Callers are expected to pick the next
+// proposer as Peers[view % len(Peers)].
+func (n *Node) TriggerViewChange() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.view++
+	return n.view
+}
+
+// ProposerForView returns the node ID responsible for proposing in view.
+func (n *Node) ProposerForView(view uint64) string {
+	if len(n.Peers) == 0 {
+		return ""
+	}
+	return n.Peers[int(view)%len(n.Peers)]
+}
@@ -0,0 +1,34 @@
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+)
+
+// ProposeAndAwaitCommit proposes candidate and blocks until 2F+1 nodes have
+// committed to it (or timeout elapses), returning the aggregated
+// signatures in the same call that would otherwise have just signed
+// locally. This lets BeginVerifiedMatch stay synchronous even though
+// consensus itself is message-driven. Concurrent calls (distinct
+// candidates proposed on the same node at once) each get their own
+// waiter, registered under n.mu, rather than racing on a shared
+// OnCommitted field.
+func (n *Node) ProposeAndAwaitCommit(candidate *arcadeum.MatchVerifiedMessage, timeout time.Duration) ([]*cr.Signature, error) {
+	done := n.addWaiter(candidate.MatchHash)
+
+	if err := n.ProposeMatch(candidate); err != nil {
+		n.removeWaiter(candidate.MatchHash, done)
+		return nil, err
+	}
+
+	select {
+	case sigs := <-done:
+		return sigs, nil
+	case <-time.After(timeout):
+		n.removeWaiter(candidate.MatchHash, done)
+		return nil, fmt.Errorf("consensus: timed out waiting for quorum commit on match %x", candidate.MatchHash)
+	}
+}
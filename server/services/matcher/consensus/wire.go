@@ -0,0 +1,13 @@
+package consensus
+
+import "encoding/json"
+
+func encode(msg ConsensusMessage) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func decode(payload []byte) (ConsensusMessage, error) {
+	var msg ConsensusMessage
+	err := json.Unmarshal(payload, &msg)
+	return msg, err
+}
@@ -0,0 +1,30 @@
+package matcher
+
+import "github.com/horizon-games/arcadeum/server/services/matcher/consensus"
+
+// consensusTransport adapts the existing Redis-backed PubSubManager (used
+// to relay gameplay messages between players) to consensus.Transport, so
+// quorum nodes can exchange PrePrepare/Prepare/Commit messages over the
+// same pub/sub infrastructure instead of standing up a separate one.
+type consensusTransport struct {
+	pubsub *PubSubManager
+}
+
+var _ consensus.Transport = (*consensusTransport)(nil)
+
+func newConsensusTransport(pubsub *PubSubManager) *consensusTransport {
+	return &consensusTransport{pubsub: pubsub}
+}
+
+func (t *consensusTransport) Publish(topic string, payload []byte) error {
+	return t.pubsub.Publish(topic, Message{
+		Meta:    &Meta{Code: MSG},
+		Payload: string(payload),
+	})
+}
+
+func (t *consensusTransport) Subscribe(topic string, handler func(payload []byte)) error {
+	return t.pubsub.Subscribe(topic, func(msg Message) {
+		handler([]byte(msg.Payload))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"time"
 
+	"context"
 	"encoding/json"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -20,11 +21,21 @@ import (
 	"github.com/horizon-games/arcadeum/server/config"
 	"github.com/horizon-games/arcadeum/server/services/arcadeum"
 	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+	"github.com/horizon-games/arcadeum/server/services/matcher/beacon"
+	"github.com/horizon-games/arcadeum/server/services/matcher/audit"
+	"github.com/horizon-games/arcadeum/server/services/matcher/consensus"
+	"github.com/horizon-games/arcadeum/server/services/matcher/seedvalidator"
 	"github.com/horizon-games/arcadeum/server/services/util"
 	"github.com/satori/go.uuid"
 	"strconv"
 )
 
+// errNoQuorumSignatures is returned if a quorum round commits with no
+// co-signatures at all, which should never happen once ProposeAndAwaitCommit
+// returns successfully -- guarded against here so a bug there surfaces as an
+// error instead of a nil SignatureMatchHash downstream.
+var errNoQuorumSignatures = errors.New("matcher: quorum committed with no signatures")
+
 type Code int
 type Status int
 
@@ -73,6 +84,11 @@ type Service struct {
 	ArcClient *arcadeum.Client
 	ENV       *config.ENVConfig
 	Config    *config.MatcherConfig
+	Beacon         *beacon.BeaconNetworks
+	Quorum         *consensus.Node // nil when this matcher signs matches alone
+	AuditLog       *audit.Log
+	Checkpointer   *audit.Checkpointer
+	SeedValidators *seedvalidator.Registry
 	*SessionManager
 	*PubSubManager
 }
@@ -91,9 +107,44 @@ func NewService(
 		ENV:            env,
 		Config:         cfg,
 		ArcClient:      arcadeum.NewArcadeumClient(ethcfg, arcconfig),
+		Beacon:         beacon.NewBeaconNetworksFromConfig(cfg.BeaconNetworks),
 		SessionManager: sessMgr,
 		PubSubManager:  NewPubSubManager(sessMgr),
 	}
+	service.SeedValidators = seedvalidator.NewRegistry()
+	// Games that predate this registry keep working unchanged against the
+	// original Ethereum path.
+	service.SeedValidators.SetFallback(seedvalidator.NewEthereumValidator(service.ArcClient))
+	for _, g := range cfg.SolanaGames {
+		validator := seedvalidator.NewSolanaValidator(g.RPCEndpoint, g.ProgramPubkey)
+		for _, owner := range g.Owners {
+			validator.LinkOwner(owner.Account, owner.DeckAccount, owner.OwnerPubkey)
+		}
+		service.SeedValidators.Register(g.GameID, validator)
+	}
+	blsVerifier := seedvalidator.NewBLS12381Verifier()
+	for _, g := range cfg.OffchainGames {
+		service.SeedValidators.Register(g.GameID, seedvalidator.NewOffchainValidator(g.OraclePubkey, blsVerifier))
+	}
+
+	auditLog := audit.NewLog(audit.NewRedisLeafStore(rediscfg, cfg.AuditLogKey))
+	service.AuditLog = auditLog
+	service.Checkpointer = audit.NewCheckpointer(
+		auditLog,
+		&chainCheckpointPoster{service: service},
+		cfg.AuditCheckpointLeafInterval,
+		cfg.AuditCheckpointInterval)
+	go service.Checkpointer.Run(nil)
+
+	if len(cfg.QuorumPeers) > 1 {
+		service.Quorum = consensus.NewNode(
+			cfg.QuorumNodeID,
+			service.PrivKey(),
+			cfg.QuorumPeers,
+			cfg.QuorumTopic,
+			newConsensusTransport(service.PubSubManager),
+			service.validateCandidateMatch)
+	}
 	go service.ArcClient.HandleWithdrawalStarted(service)
 	return service
 }
@@ -103,11 +154,11 @@ func (s *Service) OnWithdrawalStarted(event *arcadeum.ArcadeumWithdrawalStarted)
 	account := event.Account
 	sess, err := s.GetSessionByAccount(&account)
 	if err != nil {
-		log.Println("ERROR: Could not find session %s", err.Error())
+		log.Printf("ERROR: Could not find session: %s", err.Error())
 		return
 	}
 	if sess.IsEmpty() {
-		log.Println("ERROR: empty session for account %s", account.String())
+		log.Printf("ERROR: empty session for account %s", account.String())
 		return
 	}
 	contract := s.ArcClient.ArcadeumContract
@@ -126,22 +177,33 @@ func (s *Service) OnWithdrawalStarted(event *arcadeum.ArcadeumWithdrawalStarted)
 		return
 	}
 
+	// A player who never produced a timestamp proof, or whose match was
+	// never verified by this matcher, has no liveness proof to offer at
+	// all -- that's grounds to slash on its own, without dereferencing
+	// signatures that don't exist.
 	var playerR, playerS, sessR, sessS [32]byte
-	copy(playerR[:], player.TimestampSig.R)
-	copy(playerS[:], player.TimestampSig.S)
-	copy(sessR[:], sess.Signature.R)
-	copy(sessS[:], sess.Signature.S)
+	var playerV, sessV byte
+	if player.TimestampSig != nil && sess.Signature != nil {
+		copy(playerR[:], player.TimestampSig.R)
+		copy(playerS[:], player.TimestampSig.S)
+		copy(sessR[:], sess.Signature.R)
+		copy(sessS[:], sess.Signature.S)
+		playerV = player.TimestampSig.V
+		sessV = sess.Signature.V
+	}
+
 	canWithdraw, err := contract.CanStopWithdrawalXXX(
 		&bind.CallOpts{},
+		account,
 		big.NewInt(sess.Timestamp),
-		player.TimestampSig.V,
+		playerV,
 		playerR,
 		playerS,
-		sess.Signature.V,
+		sessV,
 		sessR,
 		sessS)
 	if err != nil {
-		log.Printf("ERROR: Could not read CanStopWithdrawal() value from blockchain", err)
+		log.Printf("ERROR: Could not read CanStopWithdrawal() value from blockchain: %s", err.Error())
 		return
 	}
 	if !canWithdraw { // Slash player
@@ -152,11 +214,12 @@ func (s *Service) OnWithdrawalStarted(event *arcadeum.ArcadeumWithdrawalStarted)
 		opts.GasPrice = nil // use price oracle
 		_, err := contract.StopWithdrawalXXX(
 			opts,
+			account,
 			big.NewInt(sess.Timestamp),
-			player.TimestampSig.V,
+			playerV,
 			playerR,
 			playerS,
-			sess.Signature.V,
+			sessV,
 			sessR,
 			sessS)
 		if err != nil {
@@ -230,19 +293,23 @@ func (s *Service) Authenticate(token *Token) (*MatchResponse, error) {
 	}
 	status, err := s.ArcClient.GetStakedStatus(address)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Error validating stake.", err))
+		return nil, fmt.Errorf("Error validating stake: %s", err)
 	}
 	if status == arcadeum.STAKED {
-		owner, err := s.ArcClient.IsSecretSeedValid(token.GameID, address, token.Seed)
+		validator, err := s.SeedValidators.For(token.GameID)
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Error verifying seed ownership.", err))
+			return nil, err
+		}
+		owner, err := validator.ValidateOwnership(token.GameID, address, token.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("Error verifying seed ownership: %s", err)
 		}
 		if !owner {
 			return nil, errors.New("Invalid seed ownership.")
 		}
-		rank, err := s.ArcClient.CalculateRank(token.GameID, token.Seed)
+		rank, err := validator.Rank(token.GameID, token.Seed)
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Error calculating rank.", err))
+			return nil, fmt.Errorf("Error calculating rank: %s", err)
 		}
 		return &MatchResponse{
 			Account: address,
@@ -265,7 +332,14 @@ func (s *Service) HandleMatchResponses() {
 
 // Invariant: rp has been authenticated
 func (s *Service) Match(rp *MatchResponse) {
-	uuid, err := s.TakeRandomSessionByRank(rp.Rank)
+	entry, err := s.LatestBeaconEntry(context.Background())
+	if err != nil {
+		s.Close(fmt.Sprintf("Error fetching randomness beacon %s", err.Error()), rp)
+		return
+	}
+
+	index := s.BeaconPoolIndex(entry, rp.Rank)
+	uuid, err := s.TakeSessionByRankAtIndex(rp.Rank, index)
 	if err != nil {
 		s.Close(fmt.Sprintf("Error finding opponent %s", err.Error()), rp)
 		return
@@ -273,7 +347,7 @@ func (s *Service) Match(rp *MatchResponse) {
 	if uuid.IsEmpty() {
 		err = s.AddToMatchPool(rp)
 	} else {
-		err = s.InitGame(uuid, rp)
+		err = s.InitGame(uuid, rp, entry)
 	}
 	if err != nil {
 		s.ReaddToMatchPool(rp.Rank, uuid)
@@ -281,7 +355,40 @@ func (s *Service) Match(rp *MatchResponse) {
 	}
 }
 
-func (s *Service) InitGame(uid UUID, r *MatchResponse) error {
+// LatestBeaconEntry fetches the most recent entry from the beacon chain
+// currently accepting new rounds, so Match() can derive a verifiable
+// opponent index from it.
+func (s *Service) LatestBeaconEntry(ctx context.Context) (beacon.BeaconEntry, error) {
+	chain, err := s.Beacon.Active()
+	if err != nil {
+		return beacon.BeaconEntry{}, err
+	}
+	round, err := chain.LatestBeaconRound(ctx)
+	if err != nil {
+		return beacon.BeaconEntry{}, err
+	}
+	return chain.Entry(ctx, round)
+}
+
+// BeaconPoolIndex derives which waiting session to dequeue from the
+// per-rank pool, as keccak256(entry.Randomness || rank) mod len(pool).
+// Because the beacon round is published independently of the matcher,
+// both players (and the on-chain slashing path, via the round + signature
+// carried on MatchVerifiedMessage) can recompute this index and confirm
+// the matcher didn't hand-pick their opponent.
+func (s *Service) BeaconPoolIndex(entry beacon.BeaconEntry, rank uint32) int {
+	poolSize := s.PoolSizeByRank(rank)
+	if poolSize <= 0 {
+		return 0
+	}
+	rankBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(rankBytes, rank)
+	digest := crypto.Keccak256(entry.Randomness, rankBytes)
+	index := new(big.Int).Mod(new(big.Int).SetBytes(digest), big.NewInt(int64(poolSize)))
+	return int(index.Int64())
+}
+
+func (s *Service) InitGame(uid UUID, r *MatchResponse, entry beacon.BeaconEntry) error {
 	session, err := s.GetSessionByID(uid)
 	if err != nil {
 		return err
@@ -296,6 +403,8 @@ func (s *Service) InitGame(uid UUID, r *MatchResponse) error {
 	newSess.Player1.Index = 1
 	session.Player2 = newSess.Player1
 	session.Timestamp = time.Now().Unix()
+	session.BeaconRound = entry.Round
+	session.BeaconSignature = entry.Signature
 	err = s.UpdateSession(session)
 	if err != nil {
 		return err
@@ -335,6 +444,15 @@ func (srv *Service) BeginVerifiedMatch(sess *Session) error {
 	if err != nil {
 		return err
 	}
+	if srv.AuditLog != nil {
+		leafIndex, err := srv.AuditLog.Append(msg)
+		if err != nil {
+			log.Println("ERROR: could not append match to audit log", err)
+		} else if srv.Checkpointer != nil {
+			srv.Checkpointer.MaybeCheckpointOnAppend(leafIndex + 1)
+		}
+	}
+
 	msg.PlayerIndex = sess.Player1.Index
 	msg.SignatureOpponentSubkey = sess.Player2.Token.SubKeySignature
 	sess.Signature = msg.SignatureMatchHash
@@ -382,10 +500,12 @@ func (s *Service) NewKeyedTransactor() *bind.TransactOpts {
 
 func (srv *Service) BuildMatchVerifiedMessageWithSignature(s *Session) (*arcadeum.MatchVerifiedMessage, error) {
 	msg := &arcadeum.MatchVerifiedMessage{
-		Accounts:    [2]common.Address{*s.Player1.Account, *s.Player2.Account},
-		Subkeys:     [2]common.Address{*s.Player1.SubKey, *s.Player2.SubKey},
-		GameAddress: srv.ArcClient.GameAddress[s.GameID],
-		Timestamp:   s.Timestamp,
+		Accounts:        [2]common.Address{*s.Player1.Account, *s.Player2.Account},
+		Subkeys:         [2]common.Address{*s.Player1.SubKey, *s.Player2.SubKey},
+		GameAddress:     srv.ArcClient.GameAddress[s.GameID],
+		Timestamp:       s.Timestamp,
+		BeaconRound:     s.BeaconRound,
+		BeaconSignature: s.BeaconSignature,
 		Players: [2]*arcadeum.MatchVerifiedPlayerInfo{
 			{
 				SeedRating:         s.Player1.Rank,
@@ -405,7 +525,22 @@ func (srv *Service) BuildMatchVerifiedMessageWithSignature(s *Session) (*arcadeu
 	}
 	msg.MatchHash = hash
 
-	// Have the matcher sign
+	// If this matcher is part of a federated quorum, the match isn't valid
+	// until 2F+1 nodes have co-signed it; otherwise fall back to signing
+	// alone, exactly as a lone matcher always has.
+	if srv.Quorum != nil {
+		sigs, err := srv.Quorum.ProposeAndAwaitCommit(msg, srv.Config.ConsensusTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if len(sigs) == 0 {
+			return nil, errNoQuorumSignatures
+		}
+		msg.SignatureMatchHashSet = sigs
+		msg.SignatureMatchHash = sigs[0]
+		return msg, nil
+	}
+
 	sig, err := crypto.Sign(hash[:], srv.PrivKey())
 	if err != nil {
 		return nil, err
@@ -419,6 +554,45 @@ func (srv *Service) BuildMatchVerifiedMessageWithSignature(s *Session) (*arcadeu
 	return msg, nil
 }
 
+// validateCandidateMatch re-runs the checks a lone matcher would have made
+// before signing a MatchVerifiedMessage: both timestamp signatures must
+// recover, over keccak256(timestamp), to the candidate's subkeys, and both
+// accounts must still carry sufficient stake. Quorum
+// members run this before issuing a Prepare so a malicious proposer can't
+// get a bogus candidate co-signed.
+func (srv *Service) validateCandidateMatch(candidate *arcadeum.MatchVerifiedMessage) error {
+	tsHash := crypto.Keccak256(big.NewInt(candidate.Timestamp).Bytes())
+	for i, account := range candidate.Accounts {
+		status, err := srv.ArcClient.GetStakedStatus(account)
+		if err != nil {
+			return fmt.Errorf("validating stake for %s: %w", account.String(), err)
+		}
+		if status != arcadeum.STAKED {
+			return fmt.Errorf("account %s is not sufficiently staked", account.String())
+		}
+		sig := candidate.Players[i].SignatureTimestamp
+		if sig == nil {
+			return fmt.Errorf("missing timestamp signature for account %s", account.String())
+		}
+		sigBytes := make([]byte, 65)
+		copy(sigBytes[0:32], sig.R)
+		copy(sigBytes[32:64], sig.S)
+		sigBytes[64] = sig.V - 27
+		pub, err := crypto.SigToPub(tsHash, sigBytes)
+		if err != nil || crypto.PubkeyToAddress(*pub) != candidate.Subkeys[i] {
+			return fmt.Errorf("invalid timestamp signature for account %s", account.String())
+		}
+	}
+	hash, err := srv.ArcClient.MatchHash(candidate)
+	if err != nil {
+		return err
+	}
+	if hash != candidate.MatchHash {
+		return errors.New("candidate match hash does not match its contents")
+	}
+	return nil
+}
+
 func (s *Service) RequestTimestampProof(sess *Session) error {
 	log.Println("Requesting timestamp proof from both players")
 	message := Message{
@@ -447,10 +621,7 @@ func (s *Service) RequestTimestampProof(sess *Session) error {
 }
 
 func (s *Service) CreateSession(p *MatchResponse) (*Session, error) {
-	id, err := uuid.NewV4()
-	if err != nil {
-		return nil, err
-	}
+	id := uuid.NewV4()
 	player, err := s.BuildPlayerInfo(p)
 	if err != nil {
 		return nil, err
@@ -473,7 +644,13 @@ func (srv *Service) SignElliptic(inputs ...interface{}) (r, s *big.Int, err erro
 	return
 }
 
+// PrivKey returns the matcher's own signing key: the one injected into a
+// test ArcClient via arcadeum.NewTestClient, or else the one loaded from
+// the configured key file.
 func (srv *Service) PrivKey() *ecdsa.PrivateKey {
+	if key := srv.ArcClient.SignerKey(); key != nil {
+		return key
+	}
 	path := fmt.Sprintf("%s/%s", srv.ENV.WorkingDir, srv.Config.PrivKeyFile)
 	privkey, err := crypto.LoadECDSA(path)
 	if err != nil {
@@ -487,7 +664,7 @@ func (srv *Service) Sign(inputs ...interface{}) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return asn1.Marshal(cr.EcdsaSignature{r, s})
+	return asn1.Marshal(cr.EcdsaSignature{R: r, S: s})
 }
 
 func Compact(inputs ...interface{}) ([]byte, error) {
@@ -521,7 +698,11 @@ func IToB(data interface{}) ([]byte, error) {
 }
 
 func (s *Service) BuildPlayerInfo(p *MatchResponse) (*PlayerInfo, error) {
-	seedHash, err := s.ArcClient.PublicSeed(p.GameID, p.Seed)
+	validator, err := s.SeedValidators.For(p.GameID)
+	if err != nil {
+		return nil, err
+	}
+	seedHash, err := validator.PublicSeed(p.GameID, p.Seed)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,54 @@
+package seedvalidator
+
+import (
+	"errors"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// blsSigDST is the hash-to-curve domain separation tag oracle signatures
+// are verified under, the same IETF BLS draft scheme (G1 signature, G2
+// public key) drand's chains sign with.
+const blsSigDST = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"
+
+var g2Generator bls12381.G2Affine
+
+func init() {
+	_, _, _, g2Generator = bls12381.Generators()
+}
+
+// bls12381Verifier checks a BLS signature over BLS12-381, hashing the
+// message to G1 with a proper indifferentiable hash-to-curve (RFC 9380)
+// rather than a bare scalar multiplication of its digest: mapping H(m) =
+// digest(m)·G would make H(m)'s discrete log public, letting anyone who
+// observes a single valid oracle signature forge a signature over any
+// other message.
+type bls12381Verifier struct{}
+
+// NewBLS12381Verifier returns the default BLSVerifier used for offchain
+// games: a BLS12-381 pairing check, e(sig, g2) == e(H(message), pubkey).
+func NewBLS12381Verifier() BLSVerifier {
+	return bls12381Verifier{}
+}
+
+func (bls12381Verifier) Verify(pubkey, message, signature []byte) (bool, error) {
+	var sig bls12381.G1Affine
+	if err := sig.Unmarshal(signature); err != nil {
+		return false, errors.New("seedvalidator: malformed BLS signature")
+	}
+	var pub bls12381.G2Affine
+	if err := pub.Unmarshal(pubkey); err != nil {
+		return false, errors.New("seedvalidator: malformed BLS pubkey")
+	}
+	hm, err := bls12381.HashToG1(message, []byte(blsSigDST))
+	if err != nil {
+		return false, err
+	}
+
+	// e(sig, g2Generator) == e(hm, pub)  <=>  e(sig, g2Generator) * e(-hm, pub) == 1
+	negHM := new(bls12381.G1Affine).Neg(&hm)
+	return bls12381.PairingCheck(
+		[]bls12381.G1Affine{sig, *negHM},
+		[]bls12381.G2Affine{g2Generator, pub},
+	)
+}
@@ -0,0 +1,32 @@
+package seedvalidator
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+)
+
+// EthereumValidator is the original Arcadeum seed validation path,
+// delegating to arcadeum.Client: any non-empty seed is accepted as owned
+// and rank is derived from keccak256(seed); no game contract is read.
+// It exists so games already deployed before this registry do not need
+// to change.
+type EthereumValidator struct {
+	Client *arcadeum.Client
+}
+
+// NewEthereumValidator wraps an existing Arcadeum client.
+func NewEthereumValidator(client *arcadeum.Client) *EthereumValidator {
+	return &EthereumValidator{Client: client}
+}
+
+func (v *EthereumValidator) ValidateOwnership(gameID arcadeum.GameID, account common.Address, seed []byte) (bool, error) {
+	return v.Client.IsSecretSeedValid(gameID, account, seed)
+}
+
+func (v *EthereumValidator) Rank(gameID arcadeum.GameID, seed []byte) (uint32, error) {
+	return v.Client.CalculateRank(gameID, seed)
+}
+
+func (v *EthereumValidator) PublicSeed(gameID arcadeum.GameID, seed []byte) ([]byte, error) {
+	return v.Client.PublicSeed(gameID, seed)
+}
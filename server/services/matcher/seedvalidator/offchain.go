@@ -0,0 +1,71 @@
+package seedvalidator
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+)
+
+// blsSignatureSize is the length of a compressed BLS12-381 G1 signature,
+// the curve Arcadeum's other BLS usages (e.g. drand's randomness beacon)
+// are built on.
+const blsSignatureSize = 48
+
+// BLSVerifier abstracts the pairing check so this package doesn't need to
+// depend directly on a specific pairing library.
+type BLSVerifier interface {
+	Verify(pubkey, message, signature []byte) (bool, error)
+}
+
+// OffchainValidator accepts a BLS signature from a configured oracle
+// pubkey in place of an on-chain deck commitment, for games that don't
+// have one. The oracle is expected to sign keccak256(seed) after
+// validating ownership out of band (e.g. a verifiable-delay proof, a
+// centralized deck issuer, or another game's own ownership rules).
+type OffchainValidator struct {
+	OraclePubkey []byte
+	Verifier     BLSVerifier
+	RankFn       func(seed []byte) uint32 // defaults to keccak256(seed) low bits
+}
+
+// NewOffchainValidator builds a validator trusting signatures from
+// oraclePubkey, checked with verifier.
+func NewOffchainValidator(oraclePubkey []byte, verifier BLSVerifier) *OffchainValidator {
+	return &OffchainValidator{OraclePubkey: oraclePubkey, Verifier: verifier}
+}
+
+// ValidateOwnership expects seed to be rawSeed || BLS signature over
+// keccak256(rawSeed), matching the convention SolanaValidator uses for
+// seed || detached-signature encoding.
+func (v *OffchainValidator) ValidateOwnership(gameID arcadeum.GameID, account common.Address, seed []byte) (bool, error) {
+	if len(seed) <= blsSignatureSize {
+		return false, fmt.Errorf("seedvalidator: expected seed + %d-byte BLS signature, got %d bytes", blsSignatureSize, len(seed))
+	}
+	rawSeed := seed[:len(seed)-blsSignatureSize]
+	sig := seed[len(seed)-blsSignatureSize:]
+
+	digest := crypto.Keccak256(rawSeed)
+	return v.Verifier.Verify(v.OraclePubkey, digest, sig)
+}
+
+func (v *OffchainValidator) Rank(gameID arcadeum.GameID, seed []byte) (uint32, error) {
+	if len(seed) <= blsSignatureSize {
+		return 0, fmt.Errorf("seedvalidator: expected seed + %d-byte BLS signature, got %d bytes", blsSignatureSize, len(seed))
+	}
+	rawSeed := seed[:len(seed)-blsSignatureSize]
+	if v.RankFn != nil {
+		return v.RankFn(rawSeed), nil
+	}
+	digest := crypto.Keccak256(rawSeed)
+	return uint32(digest[28])<<24 | uint32(digest[29])<<16 | uint32(digest[30])<<8 | uint32(digest[31]), nil
+}
+
+func (v *OffchainValidator) PublicSeed(gameID arcadeum.GameID, seed []byte) ([]byte, error) {
+	if len(seed) <= blsSignatureSize {
+		return nil, fmt.Errorf("seedvalidator: expected seed + %d-byte BLS signature, got %d bytes", blsSignatureSize, len(seed))
+	}
+	rawSeed := seed[:len(seed)-blsSignatureSize]
+	return crypto.Keccak256(rawSeed), nil
+}
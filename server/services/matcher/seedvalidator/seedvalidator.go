@@ -0,0 +1,73 @@
+// Package seedvalidator decouples seed ownership and rank calculation from
+// any one chain family, so Arcadeum can match players whose seed
+// commitment lives on Ethereum, Solana, or nowhere on-chain at all.
+package seedvalidator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+)
+
+// SeedValidator proves that account owns seed for a given game, and
+// derives the rank and public commitment used for matchmaking and for the
+// MatchVerifiedMessage both players later verify.
+type SeedValidator interface {
+	// ValidateOwnership reports whether account is provably the owner of
+	// the secret seed for gameID.
+	ValidateOwnership(gameID arcadeum.GameID, account common.Address, seed []byte) (bool, error)
+
+	// Rank computes the matchmaking rank implied by seed.
+	Rank(gameID arcadeum.GameID, seed []byte) (uint32, error)
+
+	// PublicSeed derives the public commitment to seed that's safe to put
+	// in a MatchVerifiedMessage.
+	PublicSeed(gameID arcadeum.GameID, seed []byte) ([]byte, error)
+}
+
+// Registry looks up the SeedValidator responsible for a game, so
+// FindMatch doesn't need to know which chain family a game belongs to.
+type Registry struct {
+	mu         sync.RWMutex
+	validators map[arcadeum.GameID]SeedValidator
+	fallback   SeedValidator
+}
+
+// NewRegistry builds an empty registry. Register backends with Register;
+// set fallback with SetFallback if unregistered games should still
+// resolve to a default backend (e.g. the Ethereum one, for games already
+// live before this registry existed).
+func NewRegistry() *Registry {
+	return &Registry{validators: make(map[arcadeum.GameID]SeedValidator)}
+}
+
+// Register associates gameID with validator.
+func (r *Registry) Register(gameID arcadeum.GameID, validator SeedValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validators[gameID] = validator
+}
+
+// SetFallback sets the validator used for games with no explicit
+// registration.
+func (r *Registry) SetFallback(validator SeedValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = validator
+}
+
+// For returns the SeedValidator registered for gameID, or the fallback if
+// none is registered and a fallback was set.
+func (r *Registry) For(gameID arcadeum.GameID) (SeedValidator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if v, ok := r.validators[gameID]; ok {
+		return v, nil
+	}
+	if r.fallback != nil {
+		return r.fallback, nil
+	}
+	return nil, fmt.Errorf("seedvalidator: no backend registered for game %v", gameID)
+}
@@ -0,0 +1,187 @@
+package seedvalidator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/horizon-games/arcadeum/server/services/arcadeum"
+)
+
+// solanaSeedLen is the length of the raw deck seed; anything beyond that
+// in a submitted seed blob is the detached ed25519 signature over it.
+const solanaSeedLen = 32
+
+// SolanaValidator validates seed ownership for games whose deck
+// commitment lives in a Solana program account rather than an Ethereum
+// contract. Client.Seed for these games is raw seed || detached ed25519
+// signature over the raw seed; ownership is proven by verifying that
+// signature against the owner pubkey the program account was created
+// with, fetched over RPC.
+type SolanaValidator struct {
+	RPCEndpoint   string
+	HTTPClient    *http.Client
+	ProgramPubkey string // base58-encoded pubkey owning deck-commitment accounts
+
+	// OwnerPubkeys maps each Ethereum account allowed to play into the
+	// Solana ed25519 pubkey it linked its deck-commitment account with.
+	// Without this, ValidateOwnership would only prove *some* Solana
+	// account signed off on the seed, not that it was the account the
+	// caller claims to be -- letting any linked Solana signer vouch for
+	// an arbitrary Ethereum address.
+	OwnerPubkeys map[common.Address][32]byte
+
+	// DeckAccounts maps each Ethereum account to the base58-encoded
+	// Solana account holding its deck commitment. Every player posts
+	// their commitment to their own account under ProgramPubkey, so
+	// without this, fetchSeedAccount would have no way to know which
+	// account to fetch for a given claimant and ValidateOwnership could
+	// only ever check one fixed player's commitment.
+	DeckAccounts map[common.Address]string
+}
+
+// NewSolanaValidator builds a validator that checks signatures against
+// deck-owner pubkeys fetched from rpcEndpoint's getAccountInfo.
+func NewSolanaValidator(rpcEndpoint, programPubkey string) *SolanaValidator {
+	return &SolanaValidator{
+		RPCEndpoint:   rpcEndpoint,
+		HTTPClient:    http.DefaultClient,
+		ProgramPubkey: programPubkey,
+		OwnerPubkeys:  make(map[common.Address][32]byte),
+		DeckAccounts:  make(map[common.Address]string),
+	}
+}
+
+// LinkOwner registers that account's deck-commitment account is owned by
+// ownerPubkey, the binding ValidateOwnership checks the fetched account
+// against, and that the commitment itself lives at deckAccount.
+func (v *SolanaValidator) LinkOwner(account common.Address, deckAccount string, ownerPubkey [32]byte) {
+	v.OwnerPubkeys[account] = ownerPubkey
+	v.DeckAccounts[account] = deckAccount
+}
+
+func (v *SolanaValidator) ValidateOwnership(gameID arcadeum.GameID, account common.Address, seed []byte) (bool, error) {
+	rawSeed, sig, err := splitSeedAndSignature(seed)
+	if err != nil {
+		return false, err
+	}
+	expectedOwner, ok := v.OwnerPubkeys[account]
+	if !ok {
+		return false, fmt.Errorf("seedvalidator: no Solana owner pubkey linked for account %s", account)
+	}
+	deckAccount, ok := v.DeckAccounts[account]
+	if !ok {
+		return false, fmt.Errorf("seedvalidator: no Solana deck account linked for account %s", account)
+	}
+	acc, err := v.fetchSeedAccount(context.Background(), deckAccount)
+	if err != nil {
+		return false, err
+	}
+	if acc.OwnerPubkey != expectedOwner {
+		return false, nil
+	}
+	if !bytes.Equal(acc.SeedCommitment[:], crypto.Keccak256(rawSeed)) {
+		return false, nil
+	}
+	return ed25519.Verify(acc.OwnerPubkey[:], rawSeed, sig), nil
+}
+
+func (v *SolanaValidator) Rank(gameID arcadeum.GameID, seed []byte) (uint32, error) {
+	rawSeed, _, err := splitSeedAndSignature(seed)
+	if err != nil {
+		return 0, err
+	}
+	// Derived identically to the Ethereum backend, so ranks stay
+	// comparable across chains within the same matchmaking pool.
+	digest := crypto.Keccak256(rawSeed)
+	return uint32(digest[28])<<24 | uint32(digest[29])<<16 | uint32(digest[30])<<8 | uint32(digest[31]), nil
+}
+
+func (v *SolanaValidator) PublicSeed(gameID arcadeum.GameID, seed []byte) ([]byte, error) {
+	rawSeed, _, err := splitSeedAndSignature(seed)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(rawSeed), nil
+}
+
+func splitSeedAndSignature(seed []byte) (rawSeed, sig []byte, err error) {
+	if len(seed) != solanaSeedLen+ed25519.SignatureSize {
+		return nil, nil, fmt.Errorf("seedvalidator: expected %d-byte seed+signature, got %d bytes", solanaSeedLen+ed25519.SignatureSize, len(seed))
+	}
+	return seed[:solanaSeedLen], seed[solanaSeedLen:], nil
+}
+
+// seedAccount is the subset of a Solana deck-commitment account's layout
+// this validator needs: the ed25519 owner key and the seed commitment
+// hash it was created with.
+type seedAccount struct {
+	OwnerPubkey    [32]byte
+	SeedCommitment [32]byte
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcAccountInfoResponse struct {
+	Result struct {
+		Value struct {
+			Data []string `json:"data"` // [base64, encoding]
+		} `json:"value"`
+	} `json:"result"`
+}
+
+func (v *SolanaValidator) fetchSeedAccount(ctx context.Context, deckAccount string) (seedAccount, error) {
+	req := rpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "getAccountInfo",
+		Params:  []interface{}{deckAccount, map[string]string{"encoding": "base64"}},
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return seedAccount{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, v.RPCEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return seedAccount{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.HTTPClient.Do(httpReq)
+	if err != nil {
+		return seedAccount{}, fmt.Errorf("seedvalidator: solana RPC request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var parsed rpcAccountInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return seedAccount{}, err
+	}
+	if len(parsed.Result.Value.Data) == 0 {
+		return seedAccount{}, fmt.Errorf("seedvalidator: no account data returned for Solana account %s", deckAccount)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(parsed.Result.Value.Data[0])
+	if err != nil {
+		return seedAccount{}, err
+	}
+	if len(raw) < 64 {
+		return seedAccount{}, fmt.Errorf("seedvalidator: account data too short: %d bytes", len(raw))
+	}
+
+	var acc seedAccount
+	copy(acc.OwnerPubkey[:], raw[0:32])
+	copy(acc.SeedCommitment[:], raw[32:64])
+	return acc, nil
+}
@@ -0,0 +1,301 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gomodule/redigo/redis"
+	"github.com/horizon-games/arcadeum/server/config"
+	cr "github.com/horizon-games/arcadeum/server/services/crypto"
+)
+
+// UUID identifies a Session. The empty UUID is the zero value returned by
+// lookups that found nothing, so callers can check IsEmpty instead of
+// threading (value, bool) through every SessionManager method.
+type UUID string
+
+// IsEmpty reports whether id is the zero UUID.
+func (id UUID) IsEmpty() bool {
+	return id == ""
+}
+
+// Token is what a client presents to FindMatch: the subkey it's playing
+// with, proof that subkey is authorized by its owning account, and the
+// secret seed (plus whatever ownership proof the game's SeedValidator
+// backend expects appended to it).
+type Token struct {
+	GameID          config.GameID
+	Seed            []byte
+	SubKey          *common.Address
+	SubKeySignature *cr.Signature
+}
+
+// PlayerInfo is one matched player's state within a Session.
+type PlayerInfo struct {
+	Account      *common.Address
+	Rank         uint32
+	SeedHash     []byte
+	Index        uint8
+	Verified     bool
+	TimestampSig *cr.Signature
+	*Token
+}
+
+// Session is a (possibly still-waiting) match between one or two players.
+// Player2 is nil until InitGame pairs a waiting session with an opponent.
+type Session struct {
+	ID              UUID
+	GameID          config.GameID
+	Player1         *PlayerInfo
+	Player2         *PlayerInfo
+	Timestamp       int64
+	Signature       *cr.Signature
+	BeaconRound     uint64
+	BeaconSignature []byte
+}
+
+// IsEmpty reports whether s is the zero value returned by a lookup that
+// found nothing.
+func (s *Session) IsEmpty() bool {
+	return s == nil || s.ID == ""
+}
+
+// FindPlayerByAccount returns whichever of Player1/Player2 belongs to
+// account.
+func (s *Session) FindPlayerByAccount(account common.Address) (*PlayerInfo, error) {
+	if s.Player1 != nil && s.Player1.Account != nil && *s.Player1.Account == account {
+		return s.Player1, nil
+	}
+	if s.Player2 != nil && s.Player2.Account != nil && *s.Player2.Account == account {
+		return s.Player2, nil
+	}
+	return nil, fmt.Errorf("matcher: no player with account %s in session %s", account.String(), s.ID)
+}
+
+// SessionManager persists Session state and the per-rank waiting pool in
+// Redis, the same store PubSubManager uses for its subscriptions, so a
+// restarted matcher process picks up exactly where it left off.
+type SessionManager struct {
+	pool *redis.Pool
+}
+
+const (
+	sessionsKey   = "matcher:sessions"   // hash: session ID -> JSON
+	bySubKeyKey   = "matcher:bySubKey"   // hash: subkey -> session ID
+	byAccountKey  = "matcher:byAccount"  // hash: account -> session ID
+	waitingKeyFmt = "matcher:waiting:%d" // list of waiting session IDs, by rank
+)
+
+// NewSessionManager dials cfg's Redis instance.
+func NewSessionManager(cfg *config.RedisConfig) *SessionManager {
+	return &SessionManager{
+		pool: &redis.Pool{
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", cfg.Address, redis.DialPassword(cfg.Password))
+			},
+		},
+	}
+}
+
+func waitingKey(rank uint32) string {
+	return fmt.Sprintf(waitingKeyFmt, rank)
+}
+
+func (m *SessionManager) getByID(conn redis.Conn, id UUID) (*Session, error) {
+	if id.IsEmpty() {
+		return &Session{}, nil
+	}
+	raw, err := redis.Bytes(conn.Do("HGET", sessionsKey, string(id)))
+	if err == redis.ErrNil {
+		return &Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sess Session
+	if err := json.Unmarshal(raw, &sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// GetSessionByID looks up a session by its UUID.
+func (m *SessionManager) GetSessionByID(id UUID) (*Session, error) {
+	conn := m.pool.Get()
+	defer conn.Close()
+	return m.getByID(conn, id)
+}
+
+// GetSessionBySubKey looks up the session a subkey is currently playing
+// in.
+func (m *SessionManager) GetSessionBySubKey(subKey *common.Address) (*Session, error) {
+	conn := m.pool.Get()
+	defer conn.Close()
+	id, err := redis.String(conn.Do("HGET", bySubKeyKey, subKey.String()))
+	if err == redis.ErrNil {
+		return &Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.getByID(conn, UUID(id))
+}
+
+// GetSessionByAccount looks up the session an account is currently
+// matched in.
+func (m *SessionManager) GetSessionByAccount(account *common.Address) (*Session, error) {
+	conn := m.pool.Get()
+	defer conn.Close()
+	id, err := redis.String(conn.Do("HGET", byAccountKey, account.String()))
+	if err == redis.ErrNil {
+		return &Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.getByID(conn, UUID(id))
+}
+
+// UpdateSession persists sess and refreshes its subkey/account indices.
+func (m *SessionManager) UpdateSession(sess *Session) error {
+	conn := m.pool.Get()
+	defer conn.Close()
+
+	raw, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Do("HSET", sessionsKey, string(sess.ID), raw); err != nil {
+		return err
+	}
+	for _, player := range []*PlayerInfo{sess.Player1, sess.Player2} {
+		if player == nil {
+			continue
+		}
+		if player.SubKey != nil {
+			if _, err := conn.Do("HSET", bySubKeyKey, player.SubKey.String(), string(sess.ID)); err != nil {
+				return err
+			}
+		}
+		if player.Account != nil {
+			if _, err := conn.Do("HSET", byAccountKey, player.Account.String(), string(sess.ID)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddToMatchPool adds a single-player session to the waiting pool for its
+// rank, until InitGame pairs it with an opponent.
+func (m *SessionManager) AddToMatchPool(sess *Session) error {
+	if err := m.UpdateSession(sess); err != nil {
+		return err
+	}
+	conn := m.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("RPUSH", waitingKey(sess.Rank()), string(sess.ID))
+	return err
+}
+
+// WaitingPoolSize returns the number of sessions currently waiting at
+// rank.
+func (m *SessionManager) WaitingPoolSize(rank uint32) (int, error) {
+	conn := m.pool.Get()
+	defer conn.Close()
+	return redis.Int(conn.Do("LLEN", waitingKey(rank)))
+}
+
+// TakeSessionByRankAtIndex removes and returns the session waiting at
+// position index (mod the pool's size) for rank. It returns an empty UUID
+// if nothing is waiting.
+func (m *SessionManager) TakeSessionByRankAtIndex(rank uint32, index int) (UUID, error) {
+	conn := m.pool.Get()
+	defer conn.Close()
+
+	size, err := redis.Int(conn.Do("LLEN", waitingKey(rank)))
+	if err != nil {
+		return "", err
+	}
+	if size == 0 {
+		return "", nil
+	}
+	pos := index % size
+
+	id, err := redis.String(conn.Do("LINDEX", waitingKey(rank), pos))
+	if err != nil {
+		return "", err
+	}
+	if _, err := conn.Do("LSET", waitingKey(rank), pos, "__taken__"); err != nil {
+		return "", err
+	}
+	if _, err := conn.Do("LREM", waitingKey(rank), 1, "__taken__"); err != nil {
+		return "", err
+	}
+	return UUID(id), nil
+}
+
+// ReaddToMatchPool restores a session taken off the waiting pool (e.g.
+// when the caller lost a race to actually use it) back to rank's pool.
+func (m *SessionManager) ReaddToMatchPool(rank uint32, id UUID) {
+	if id.IsEmpty() {
+		return
+	}
+	conn := m.pool.Get()
+	defer conn.Close()
+	conn.Do("RPUSH", waitingKey(rank), string(id))
+}
+
+// PubSubManager relays Message payloads between players over Redis
+// pub/sub, keyed by subkey.
+type PubSubManager struct {
+	sessions *SessionManager
+	pool     *redis.Pool
+}
+
+// NewPubSubManager shares sessions' Redis connection pool for publishing
+// and subscribing.
+func NewPubSubManager(sessions *SessionManager) *PubSubManager {
+	return &PubSubManager{sessions: sessions, pool: sessions.pool}
+}
+
+// Publish sends msg to whatever is subscribed to topic (conventionally a
+// subkey's hex string).
+func (p *PubSubManager) Publish(topic string, msg Message) error {
+	conn := p.pool.Get()
+	defer conn.Close()
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("PUBLISH", topic, payload)
+	return err
+}
+
+// Subscribe registers handler to be called with every Message published to
+// topic, blocking in its own goroutine until the subscription connection
+// closes.
+func (p *PubSubManager) Subscribe(topic string, handler func(msg Message)) error {
+	conn := p.pool.Get()
+	psc := redis.PubSubConn{Conn: conn}
+	if err := psc.Subscribe(topic); err != nil {
+		conn.Close()
+		return err
+	}
+	go func() {
+		defer conn.Close()
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				var msg Message
+				if err := json.Unmarshal(v.Data, &msg); err == nil {
+					handler(msg)
+				}
+			case error:
+				return
+			}
+		}
+	}()
+	return nil
+}
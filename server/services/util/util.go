@@ -0,0 +1,14 @@
+// Package util holds small helpers shared across the server's services.
+package util
+
+import "encoding/json"
+
+// Jsonify marshals v to a JSON string, for payloads sent over the wire in
+// Message.Payload.
+func Jsonify(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}